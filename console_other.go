@@ -0,0 +1,7 @@
+//go:build !windows
+
+package main
+
+// enableColorConsole is a no-op outside Windows, where every supported
+// terminal already honors ANSI escape sequences natively.
+func enableColorConsole() {}
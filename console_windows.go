@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+// enableColorConsole turns on ANSI escape processing in the Windows console
+// host, so the ANSI color codes and \r progress line render correctly
+// instead of printing literal escape sequences. It's a no-op on modern
+// Windows Terminal, which already honors them, and fails silently if the
+// console mode can't be queried (e.g. stdout redirected to a file).
+func enableColorConsole() {
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	getConsoleMode := kernel32.NewProc("GetConsoleMode")
+	setConsoleMode := kernel32.NewProc("SetConsoleMode")
+
+	handle := syscall.Handle(os.Stdout.Fd())
+	var mode uint32
+	if ret, _, _ := getConsoleMode.Call(uintptr(handle), uintptr(unsafe.Pointer(&mode))); ret == 0 {
+		return
+	}
+	setConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+}
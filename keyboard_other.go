@@ -0,0 +1,52 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// TCGETS/TCSETS are Linux-specific ioctl numbers; other unix-likes (e.g.
+// BSD/macOS) use different ones, so enableRawMode below is a best-effort
+// attempt that simply fails closed there rather than scribbling on the
+// wrong ioctl.
+const (
+	tcgets = 0x5401
+	tcsets = 0x5402
+)
+
+var savedTermios syscall.Termios
+var rawModeActive bool
+
+// enableRawMode puts stdin into raw, no-echo mode so -keyboard-controls can
+// read single keypresses without waiting for Enter. It reports whether raw
+// mode was actually entered -- callers should treat false as "unsupported
+// here" and give up quietly rather than erroring the whole scan.
+func enableRawMode() bool {
+	fd := os.Stdin.Fd()
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, tcgets, uintptr(unsafe.Pointer(&savedTermios))); errno != 0 {
+		return false
+	}
+
+	raw := savedTermios
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, tcsets, uintptr(unsafe.Pointer(&raw))); errno != 0 {
+		return false
+	}
+
+	rawModeActive = true
+	return true
+}
+
+// disableRawMode restores whatever terminal settings enableRawMode saved
+func disableRawMode() {
+	if !rawModeActive {
+		return
+	}
+	syscall.Syscall(syscall.SYS_IOCTL, os.Stdin.Fd(), tcsets, uintptr(unsafe.Pointer(&savedTermios)))
+	rawModeActive = false
+}
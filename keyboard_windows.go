@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+// enableRawMode is unimplemented on Windows, so -keyboard-controls is a
+// silent no-op there; use -tui instead for a live in-place dashboard.
+func enableRawMode() bool { return false }
+
+// disableRawMode has nothing to restore since enableRawMode never succeeds
+func disableRawMode() {}
@@ -2,18 +2,44 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"hash/crc32"
+	"hash/fnv"
 	"io"
+	"math"
+	"math/bits"
 	"math/rand"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
 	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -39,21 +65,139 @@ const (
 
 // Result represents a single finding
 type Result struct {
-	URL         string   `json:"url"`
-	StatusCode  int      `json:"status_code"`
-	Size        int      `json:"size"`
-	WordCount   int      `json:"word_count"`
-	LineCount   int      `json:"line_count"`
-	Critical    bool     `json:"critical"`
-	Method      string   `json:"method"`
-	Timestamp   string   `json:"timestamp"`
-	Server      string   `json:"server,omitempty"`
-	PoweredBy   string   `json:"powered_by,omitempty"`
-	CurlCommand string   `json:"curl_command"`
-	UserAgent   string   `json:"user_agent"`
-	SecretFound bool     `json:"secret_found"`
-	SecretTypes []string `json:"secret_types,omitempty"`
-	WAFDetected string   `json:"waf_detected,omitempty"`
+	URL                  string   `json:"url"`
+	StatusCode           int      `json:"status_code"`
+	Size                 int      `json:"size"`
+	WordCount            int      `json:"word_count"`
+	LineCount            int      `json:"line_count"`
+	Critical             bool     `json:"critical"`
+	Method               string   `json:"method"`
+	Timestamp            string   `json:"timestamp"`
+	Server               string   `json:"server,omitempty"`
+	PoweredBy            string   `json:"powered_by,omitempty"`
+	CurlCommand          string   `json:"curl_command"`
+	UserAgent            string   `json:"user_agent"`
+	SecretFound          bool     `json:"secret_found"`
+	SecretTypes          []string `json:"secret_types,omitempty"`
+	SecretClassification []string `json:"secret_classification,omitempty"`
+	LiveSecrets          []string `json:"live_secrets,omitempty"`
+	BodyMatch            string   `json:"body_match,omitempty"`
+	Word                 string   `json:"word,omitempty"`
+	Source               string   `json:"source,omitempty"`
+	WAFDetected          string   `json:"waf_detected,omitempty"`
+	Tags                 []string `json:"tags,omitempty"`
+	// Notes holds free-text analyst annotations added via "capsaicin
+	// annotate", each prefixed with the RFC3339 time it was added. It's
+	// just another Result field rather than a side file, so it round-trips
+	// through the existing diff/report/SARIF code paths for free.
+	Notes                []string `json:"notes,omitempty"`
+	Obfuscation          string   `json:"obfuscation_bypass,omitempty"`
+	ContentLengthHeader  int      `json:"content_length_header,omitempty"`
+	LengthMismatch       bool     `json:"length_mismatch,omitempty"`
+	AuthScheme           string   `json:"auth_scheme,omitempty"`
+	AuthRealm            string   `json:"auth_realm,omitempty"`
+	CookieIssues         []string `json:"cookie_issues,omitempty"`
+	CSPIssues            []string `json:"csp_issues,omitempty"`
+	CSPThirdPartyOrigins []string `json:"csp_third_party_origins,omitempty"`
+	Category             string   `json:"category,omitempty"`
+	SchemaSample         string   `json:"schema_sample,omitempty"`
+	PIIFound             bool     `json:"pii_found,omitempty"`
+	PIITypes             []string `json:"pii_types,omitempty"`
+	// InfoDisclosure lists internal infrastructure leakage found in the
+	// response body: RFC1918 addresses, internal-only hostnames, UNC paths
+	InfoDisclosure    []string `json:"info_disclosure,omitempty"`
+	MethodHeaderDiffs []string `json:"method_header_diffs,omitempty"`
+	// HeadGetDiscrepancy notes a status or drastic length mismatch between a
+	// HEAD and GET request to the same URL, under -head-diff
+	HeadGetDiscrepancy string `json:"head_get_discrepancy,omitempty"`
+	// HeaderAnomalies lists response headers that leak internal infrastructure
+	// details: known debug/internal header names (X-Debug-Token,
+	// X-Backend-Server, etc.) and any header value containing an RFC1918
+	// private IP address
+	HeaderAnomalies []string `json:"header_anomalies,omitempty"`
+	// BypassTechnique names the 403/401 bypass mechanism that produced this
+	// result (e.g. "header-spoof"), replacing the old convention of tagging
+	// the Method field with a "+BYPASS" suffix
+	BypassTechnique string `json:"bypass_technique,omitempty"`
+	// ParentURL is the original URL a mutation/obfuscation variant was
+	// derived from, for findings whose URL differs from the path that was
+	// actually under test (e.g. a mutation's ".bak" suffix)
+	ParentURL string `json:"parent_url,omitempty"`
+	// MutationTechnique names which generateMutations variant produced this
+	// result (e.g. "duplicated-segment", "param-pollution")
+	MutationTechnique string `json:"mutation_technique,omitempty"`
+	// BehaviorDiff summarizes how this mutation's response differed from its
+	// ParentURL's, when it did -- a status or size change worth a closer look
+	BehaviorDiff string `json:"behavior_diff,omitempty"`
+	// ResponseTimeMs is how long this request took end-to-end, in
+	// milliseconds, feeding the per-target p50/p95/p99 latency stats in the
+	// -summary output and the /metrics endpoint
+	ResponseTimeMs int64 `json:"response_time_ms,omitempty"`
+	// Protocol is the HTTP version the response came back over (e.g.
+	// "HTTP/1.1", or "HTTP/1.0" for a -protocol-downgrade retry)
+	Protocol string `json:"protocol,omitempty"`
+	// AltSvc is the raw Alt-Svc response header, captured under -http3 to
+	// flag targets that advertise HTTP/3 support even though this tool has
+	// no QUIC client to actually speak it
+	AltSvc string `json:"alt_svc,omitempty"`
+	// TLSVersion/TLSCipher are the negotiated TLS version (e.g. "TLS 1.2")
+	// and cipher suite name for an https result, empty for plain http
+	TLSVersion string `json:"tls_version,omitempty"`
+	TLSCipher  string `json:"tls_cipher,omitempty"`
+	// RateLimitLimit/Remaining/Reset are the raw values of whichever
+	// X-RateLimit-*/RateLimit-* header variant the target sent, recon data
+	// for -summary's per-target rate-limit policy and the adaptive
+	// throttling engine
+	RateLimitLimit     string `json:"rate_limit_limit,omitempty"`
+	RateLimitRemaining string `json:"rate_limit_remaining,omitempty"`
+	RateLimitReset     string `json:"rate_limit_reset,omitempty"`
+	// Vhost is the Host header used to reach this result under -vhost mode,
+	// so path findings can be attributed to the virtual host that served them
+	Vhost string `json:"vhost,omitempty"`
+	// ContentHash is a SHA-256 hex digest of the response body, used to
+	// collapse findings that are byte-identical under different paths
+	// (e.g. word, word.php, word/) into one result with AlternateURLs
+	ContentHash   string   `json:"content_hash,omitempty"`
+	AlternateURLs []string `json:"alternate_urls,omitempty"`
+	// RedirectLocation is the raw Location header on a 3xx response
+	RedirectLocation string `json:"redirect_location,omitempty"`
+	// RedirectSeededPath is the canonical directory path recursion was
+	// actually seeded from when a 301/302 pointed at a same-host path
+	// other than the requested one (e.g. /admin -> /admin/); the original
+	// requested path is still URL above, this just records the destination
+	// isDirectory/extractPath would otherwise lose
+	RedirectSeededPath string `json:"redirect_seeded_path,omitempty"`
+	// RedirectChain records each hop's "status url" under -follow-redirects,
+	// in the order they were followed; FinalURL and FinalStatusCode are the
+	// chain's last entry, broken out for convenience. OutOfScope is set if
+	// the chain was cut short because a hop left the scanned target's host
+	RedirectChain      []string `json:"redirect_chain,omitempty"`
+	FinalURL           string   `json:"final_url,omitempty"`
+	FinalStatusCode    int      `json:"final_status_code,omitempty"`
+	OutOfScopeRedirect bool     `json:"out_of_scope_redirect,omitempty"`
+	// Headers holds the response headers selected by -capture-headers (or all
+	// of them, under -capture-headers=*), for triage fields beyond the
+	// hardcoded Server/PoweredBy - Content-Type, Location, WWW-Authenticate,
+	// cache headers, etc.
+	Headers map[string]string `json:"headers,omitempty"`
+	// ContentType is the response's Content-Type header, stripped of any
+	// charset/boundary parameters, for -mt/-ft MIME-type matching and filtering
+	ContentType string `json:"content_type,omitempty"`
+	// simHash is the response body's fuzzy content hash, compared against
+	// calibration signatures to catch soft-404s whose size has drifted
+	simHash uint64
+	// FirstSeen and LastSeen track a finding's age across repeated scans run
+	// with -baseline pointed at the prior run's -o output: FirstSeen carries
+	// forward from the matching baseline entry (or is set to now, for a
+	// finding appearing for the first time), and LastSeen is always now
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	// RetryAfter is the server's requested backoff on a 429/503 response,
+	// parsed from the Retry-After header (either delay-seconds or HTTP-date form)
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+	// RetryCount is how many times -retries made this request retry a
+	// network error or plain 5xx before this final result was recorded
+	RetryCount int `json:"retry_count,omitempty"`
 }
 
 // Task represents a scanning task with depth tracking
@@ -61,6 +205,19 @@ type Task struct {
 	TargetURL string
 	Path      string
 	Depth     int
+	// WordIndex is this task's position in the wordlist (-1 if the task did
+	// not originate directly from a wordlist pass, e.g. a bypass mutation),
+	// used to report resumable per-target progress through the list
+	WordIndex int
+	// Word and Source record provenance for wordlist efficacy analysis: which
+	// wordlist entry produced this task, and which pass generated it
+	// ("wordlist", "recursion")
+	Word   string
+	Source string
+	// IsBase marks the bare-word task (no extension appended), as opposed to
+	// one of its extension-variant siblings; used by the 404-family early
+	// exit to tell which task's outcome should gate the others
+	IsBase bool
 }
 
 // ResponseSignature holds the characteristics of a response for filtering
@@ -69,32 +226,890 @@ type ResponseSignature struct {
 	Size       int
 	WordCount  int
 	LineCount  int
+	// SimHash is a 64-bit fuzzy content hash (see simhash64), used to catch
+	// custom 404 pages whose body is near-identical to a calibration probe's
+	// but whose byte size drifts past the plain 5% size-diff heuristic
+	SimHash uint64
 }
 
 // Config holds all configuration options
 type Config struct {
-	TargetURL     string
-	Wordlist      string
-	Threads       int
-	Extensions    []string
-	Timeout       int
-	OutputFile    string
-	HTMLReport    string
-	Verbose       bool
-	MaxDepth      int
-	CustomHeaders map[string]string
+	TargetURL           string
+	Wordlist            string
+	Threads             int
+	Extensions          []string
+	Timeout             int
+	OutputFile          string
+	HTMLReport          string
+	Verbose             bool
+	MaxDepth            int
+	CustomHeaders       map[string]string
+	TagRules            []TagRule
+	Aggressive          bool
+	AllowDestructive    bool
+	OutputByStatusDir   string
+	WordlistReportFile  string
+	BaselineFile        string
+	VerboseSample       float64
+	TraceURLPattern     *regexp.Regexp
+	RampUp              time.Duration
+	Stream              bool
+	PreferIPv6          bool
+	PreferIPv4          bool
+	GroupOutput         bool
+	GroupFlush          time.Duration
+	ExcludeKnownFile    string
+	CanaryLogFile       string
+	SpoofIP             string
+	ProbeUploads        bool
+	RateLimit           float64
+	RateLimitPerTarget  float64
+	Jitter              time.Duration
+	ValidateSecrets     bool
+	MatchCodeRanges     [][2]int
+	FilterCodeRanges    [][2]int
+	MatchSizeRanges     [][2]int
+	FilterSizeRanges    [][2]int
+	MatchWordRanges     [][2]int
+	FilterWordRanges    [][2]int
+	MatchLineRanges     [][2]int
+	FilterLineRanges    [][2]int
+	MetricsAddr         string
+	HealthInterval      time.Duration
+	MatchBodyRegex      *regexp.Regexp
+	FilterBodyRegex     *regexp.Regexp
+	RequestMethod       string
+	RequestBody         string
+	ContentType         string
+	CalibrationTimeout  int
+	HeavyTimeout        int
+	AllowIPRanges       []*net.IPNet
+	DenyIPRanges        []*net.IPNet
+	ProxyURLs           []string
+	ProxyHealthInterval time.Duration
+	DNSMode             bool
+	DNSResolvers        []string
+	DNSProbeHTTP        bool
+	WAFSignaturesFile   string
+	ProtocolDowngrade   bool
+	SARIFReportFile     string
+	VhostMode           bool
+	VhostDomain         string
+	InDocker            bool
+	DockerImage         string
+	DockerNetwork       string
+	RespectRobots       bool
+	FailOn              []string
+	FollowRedirects     bool
+	MaxRedirects        int
+	CalibrationPaths    []string
+	CaptureHeaders      []string
+	MatchContentTypes   []string
+	FilterContentTypes  []string
+	SPAAware            bool
+	SPAPrefixes         []string
+	CTExpand            bool
+	SummaryFile         string
+	HarvestFile         string
+	Retries             int
+	RetryDelay          time.Duration
+	// Resolvers, if set, routes all DNS lookups made while dialing scanned
+	// targets (not just the -dns subdomain brute-force module) through these
+	// resolver addresses instead of the system resolver
+	Resolvers []string
+	// HostsOverride maps a hostname to an IP address, resolved before
+	// Resolvers, for split-horizon DNS and pre-production hosts that don't
+	// resolve publicly yet
+	HostsOverride map[string]string
+	// EvidenceDir, if set, enables -evidence-mode: the full response body of
+	// every Critical or secret finding is written here under its content
+	// hash, with a signed, timestamped manifest recorded for legal evidence
+	EvidenceDir string
+	// EvidenceKeyFile points at the file holding the hex-encoded HMAC key to
+	// sign the evidence manifest with (falls back to evidenceKeyEnvVar)
+	EvidenceKeyFile string
+	// HeadDiffCheck enables -head-diff: an extra HEAD request issued against
+	// every interesting GET result to flag status/length discrepancies
+	// between the two methods
+	HeadDiffCheck bool
+	// Insecure skips TLS certificate verification (-k/-insecure)
+	Insecure bool
+	// TLSMinVersion/TLSMaxVersion bound the TLS version to negotiate (e.g.
+	// "1.0"), for testing legacy endpoints that reject modern defaults or
+	// servers that mishandle newer ones
+	TLSMinVersion string
+	TLSMaxVersion string
+	// SNI overrides the TLS ServerName sent in the ClientHello, independent
+	// of the request's Host header
+	SNI string
+	// TLSCertFile/TLSKeyFile are a client certificate/key pair for mutual TLS
+	TLSCertFile string
+	TLSKeyFile  string
+	// HTTP2 enables -http2: force-attempt an HTTP/2 connection even though
+	// the custom dialer/TLS config above would otherwise make Go's transport
+	// silently fall back to HTTP/1.1
+	HTTP2 bool
+	// HTTP3 enables -http3: the standard library has no QUIC client, so this
+	// doesn't speak HTTP/3 -- it records whether a target advertises h3
+	// support via its Alt-Svc header, which is still useful recon for
+	// endpoints that prioritize or require it
+	HTTP3 bool
+	// MatchProtocols/FilterProtocols restrict reporting to, or exclude,
+	// results by negotiated protocol (e.g. "HTTP/2.0"), for endpoints that
+	// behave differently across HTTP versions
+	MatchProtocols  []string
+	FilterProtocols []string
+	// RetryTimeouts enables -retry-timeouts: a request that times out is
+	// queued for a second attempt at TimeoutRetryConcurrency, once the main
+	// scan's task queue has drained, instead of being dropped as a
+	// permanent error -- slow endpoints are often the most interesting ones
+	RetryTimeouts bool
+	// TimeoutRetryConcurrency caps how many of the timed-out tasks above run
+	// at once during that end-of-scan pass, so a slow/overloaded target
+	// isn't hammered the same way that caused the timeouts in the first place
+	TimeoutRetryConcurrency int
+	// RequestTemplate, if set via -request, overrides -X/-d/-data/headers
+	// entirely: every request is sent exactly as parsed from the raw
+	// request file, with "FUZZ" substituted in the path, header values, and
+	// body, so complex authenticated requests don't need reconstructing
+	RequestTemplate *rawRequestTemplate
+	// PortsPreflight enables -ports: a quick TCP probe of these ports
+	// against every bare-host target before fuzzing starts, expanding
+	// responsive ones into full http(s) targets
+	PortsPreflight []int
+	// CompareBaselineFile points -compare-baseline at a signed inventory
+	// previously produced by "capsaicin baseline", diffed against this
+	// scan's findings at the end of the run so new/changed reachable paths
+	// fail CI the same way -fail-on does
+	CompareBaselineFile string
+	// BaselineKeyFile points at the file holding the hex-encoded HMAC key
+	// CompareBaselineFile was signed with (falls back to baselineKeyEnvVar)
+	BaselineKeyFile string
+	// LoginConfigFile points -login-config at a JSON file describing a
+	// scripted login request and a regex to pull the session token out of
+	// its response, executed before scanning and re-executed whenever a
+	// target's responses look like the session has expired
+	LoginConfigFile string
+	// TUI enables -tui: a live-redrawn dashboard (progress bar, counters,
+	// recent findings) in place of the normal scrolling console output.
+	// This is a stdlib-only approximation of a full terminal UI -- there's
+	// no bubbletea/tcell dependency available in this build, so it has no
+	// scrolling/filtering or pause/thread-count keybindings
+	TUI bool
+	// KeyboardControls enables -keyboard-controls: runtime hotkeys in the
+	// normal scrolling console mode (p pause/resume, +/- adjust active
+	// thread count, s print a stats snapshot, q finish gracefully), read
+	// from a raw-mode stdin like ffuf's interactive mode. Linux-only --
+	// raw mode can't be entered elsewhere, so it's a silent no-op there
+	KeyboardControls bool
+	// SecretScanMaxBytes caps full secret-pattern scanning to responses at
+	// or under this many bytes (0 uses defaultSecretScanMaxBytes); paired
+	// with a textual-Content-Type check, so secret detection doesn't burn
+	// the full regex set against every large binary asset in a wordlist run
+	SecretScanMaxBytes int
+	// DeepSecrets enables -deep-secrets: for every page that triggers a
+	// finding, also fetch and scan its linked .js/.json/.txt assets one
+	// level deep -- not recursive -- for additional secret material
+	DeepSecrets bool
+	// LocalMode enables -local: a profile for scanning your own
+	// loopback/private staging targets, where there's no adversary to
+	// evade. It disables UA rotation and WAF-detection overhead and drops
+	// -jitter to 0, then raises the default thread count, since none of
+	// the stealth/evasion machinery serves a purpose against your own box
+	LocalMode bool
+	// NotifyURLs are -notify sinks (slack://..., discord://..., telegram://...)
+	// that receive a message per Critical finding as it's collected, plus a
+	// summary-on-complete in finishScan
+	NotifyURLs []string
 }
 
 // Stats holds runtime statistics
 type Stats struct {
-	Total      int64
-	Processed  int64
-	Found      int64
-	Errors     int64
-	Secrets    int64
-	WAFHits    int64
-	StartTime  time.Time
-	ErrorMutex sync.Mutex
+	Total              int64
+	Processed          int64
+	Found              int64
+	Errors             int64
+	Secrets            int64
+	PII                int64
+	WAFHits            int64
+	Throttled          int64
+	InfoDisclosure     int64
+	DestructiveSkipped int64
+	StartTime          time.Time
+	ErrorMutex         sync.Mutex
+}
+
+// CanaryRecord is one canary value (a bypass header, calibration probe, or
+// parameter mutation) emitted during a scan, kept so a later log review can
+// attribute any side effect it caused back to the specific test that sent it
+type CanaryRecord struct {
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	Context   string `json:"context"`
+	Timestamp string `json:"timestamp"`
+}
+
+var (
+	canaryMu      sync.Mutex
+	canaryRecords []CanaryRecord
+)
+
+// recordCanary appends a canary value to the scan-wide registry
+func recordCanary(kind, value, context string) {
+	canaryMu.Lock()
+	defer canaryMu.Unlock()
+	canaryRecords = append(canaryRecords, CanaryRecord{
+		Kind:      kind,
+		Value:     value,
+		Context:   context,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// saveCanaryLog writes the scan's canary registry to disk as JSON metadata,
+// separate from the findings output, for later correlation with server logs
+func saveCanaryLog(path string) error {
+	canaryMu.Lock()
+	records := make([]CanaryRecord, len(canaryRecords))
+	copy(records, canaryRecords)
+	canaryMu.Unlock()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// EvidenceRecord captures one Critical/secret finding's full response body
+// at scan time for -evidence-mode: the body is written to -evidence-dir
+// under its content hash, and this record binds the hash to an HMAC over
+// this scan's evidence key plus the timestamp it was captured, so later
+// remediation disputes can be resolved against evidence frozen at scan time
+type EvidenceRecord struct {
+	URL       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+	SHA256    string `json:"sha256"`
+	HMAC      string `json:"hmac"`
+	FilePath  string `json:"file_path"`
+}
+
+var (
+	evidenceMu      sync.Mutex
+	evidenceRecords []EvidenceRecord
+	evidenceKey     []byte
+)
+
+// evidenceKeyEnvVar holds the hex-encoded HMAC key used to sign the evidence
+// manifest, kept out of the manifest itself -- see resolveEvidenceKey
+const evidenceKeyEnvVar = "CAPSAICIN_EVIDENCE_KEY"
+
+// resolveEvidenceKey loads the evidence HMAC key from -evidence-key-file if
+// given, falling back to evidenceKeyEnvVar. It never reads the key from the
+// evidence manifest itself -- that would defeat the signature's purpose
+func resolveEvidenceKey(keyFile string) ([]byte, error) {
+	var hexKey string
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read -evidence-key-file: %w", err)
+		}
+		hexKey = strings.TrimSpace(string(data))
+	} else {
+		hexKey = strings.TrimSpace(os.Getenv(evidenceKeyEnvVar))
+	}
+	if hexKey == "" {
+		return nil, fmt.Errorf("no evidence HMAC key configured -- set -evidence-key-file or %s", evidenceKeyEnvVar)
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("evidence HMAC key is not valid hex: %w", err)
+	}
+	return key, nil
+}
+
+// evidenceHMACKey lazily resolves this scan's HMAC key from keyFile on first
+// use, so every record in one run is signed under the same key. If neither
+// -evidence-key-file nor evidenceKeyEnvVar is configured, it generates a
+// random key and warns once -- the scan still needs to capture evidence,
+// but whoever runs it has to be told to save the key out of band
+func evidenceHMACKey(keyFile string) []byte {
+	evidenceMu.Lock()
+	defer evidenceMu.Unlock()
+	if evidenceKey == nil {
+		key, err := resolveEvidenceKey(keyFile)
+		if err != nil {
+			key = make([]byte, 32)
+			crand.Read(key)
+			fmt.Printf("%s[WARN]%s No evidence HMAC key configured -- generated one. Save it somewhere that does NOT travel with the evidence bundle, and set %s (or -evidence-key-file) to it:\n%s\n",
+				ColorYellow, ColorReset, evidenceKeyEnvVar, hex.EncodeToString(key))
+		}
+		evidenceKey = key
+	}
+	return evidenceKey
+}
+
+// captureEvidence writes body to evidenceDir under its content hash and
+// records a signed, timestamped manifest entry for it, for -evidence-mode's
+// chain-of-custody log
+func captureEvidence(evidenceDir, keyFile, targetURL, body string) error {
+	if err := os.MkdirAll(evidenceDir, 0755); err != nil {
+		return err
+	}
+
+	hash := sha256Hex([]byte(body))
+	filePath := fmt.Sprintf("%s/%s.body", strings.TrimSuffix(evidenceDir, "/"), hash)
+	if err := os.WriteFile(filePath, []byte(body), 0644); err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, evidenceHMACKey(keyFile))
+	mac.Write([]byte(hash))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	evidenceMu.Lock()
+	evidenceRecords = append(evidenceRecords, EvidenceRecord{
+		URL:       targetURL,
+		Timestamp: time.Now().Format(time.RFC3339),
+		SHA256:    hash,
+		HMAC:      signature,
+		FilePath:  filePath,
+	})
+	evidenceMu.Unlock()
+	return nil
+}
+
+// evidenceManifest is -evidence-mode's chain-of-custody log. The HMAC key
+// that signed it is never included -- see resolveEvidenceKey -- so a record
+// can only be re-verified by whoever holds -evidence-key-file/evidenceKeyEnvVar
+// out of band, the same way a baselineSnapshot is verified
+type evidenceManifest struct {
+	GeneratedAt string           `json:"generated_at"`
+	Records     []EvidenceRecord `json:"records"`
+}
+
+// saveEvidenceManifest writes the scan's evidence manifest to disk as JSON,
+// alongside the raw captured bodies already written under -evidence-dir
+func saveEvidenceManifest(path string) error {
+	evidenceMu.Lock()
+	records := make([]EvidenceRecord, len(evidenceRecords))
+	copy(records, evidenceRecords)
+	evidenceMu.Unlock()
+
+	manifest := evidenceManifest{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Records:     records,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// baselineEntry is one publicly reachable path captured by "capsaicin
+// baseline": its status, a handful of security-relevant headers, and a
+// content hash, so a later scan can detect if it changed
+type baselineEntry struct {
+	Path        string            `json:"path"`
+	StatusCode  int               `json:"status_code"`
+	ContentHash string            `json:"content_hash"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+// baselineSnapshot is "capsaicin baseline"'s output: a signed inventory of
+// a target's publicly reachable paths/headers, meant to be committed to a
+// repo and diffed against later scans via -compare-baseline. Unlike the
+// evidence manifest, the HMAC key does NOT travel with this file -- the
+// whole point is catching someone editing the committed baseline to hide
+// a newly-exposed path, and a key shipped in-band would let them just
+// recompute a matching signature. The key instead lives in
+// baselineKeyEnvVar or a -key-file kept out of the repo.
+type baselineSnapshot struct {
+	Target      string          `json:"target"`
+	GeneratedAt string          `json:"generated_at"`
+	HMAC        string          `json:"hmac"`
+	Paths       []baselineEntry `json:"paths"`
+}
+
+// baselineKeyEnvVar holds the hex-encoded HMAC key used to sign/verify a
+// baseline snapshot, kept out of the committed JSON itself -- see
+// baselineSnapshot and resolveBaselineKey
+const baselineKeyEnvVar = "CAPSAICIN_BASELINE_KEY"
+
+// resolveBaselineKey loads the baseline HMAC key from -key-file if given,
+// falling back to baselineKeyEnvVar. It never reads the key from the
+// baseline snapshot itself -- that would defeat the signature's purpose
+func resolveBaselineKey(keyFile string) ([]byte, error) {
+	var hexKey string
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read -key-file: %w", err)
+		}
+		hexKey = strings.TrimSpace(string(data))
+	} else {
+		hexKey = strings.TrimSpace(os.Getenv(baselineKeyEnvVar))
+	}
+	if hexKey == "" {
+		return nil, fmt.Errorf("no baseline HMAC key configured -- set -key-file or %s", baselineKeyEnvVar)
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("baseline HMAC key is not valid hex: %w", err)
+	}
+	return key, nil
+}
+
+// baselineHeaderNames is the set of headers captured per path in a
+// baseline snapshot -- the ones defenders most often care about drifting
+var baselineHeaderNames = []string{"Server", "X-Powered-By", "Content-Type", "Content-Security-Policy", "Strict-Transport-Security", "X-Frame-Options"}
+
+// signBaseline computes the HMAC over target plus every sorted path entry,
+// binding the inventory to the key traveling with it
+func signBaseline(target string, paths []baselineEntry, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s", target)
+	for _, p := range paths {
+		fmt.Fprintf(mac, "|%s|%d|%s", p.Path, p.StatusCode, p.ContentHash)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyBaseline reports whether a loaded baseline's HMAC still matches its
+// content under key, catching both accidental hand-edits and deliberate
+// tampering -- since key never travels with the file, recomputing a
+// matching HMAC requires whoever holds it out of band
+func verifyBaseline(b *baselineSnapshot, key []byte) bool {
+	return hmac.Equal([]byte(b.HMAC), []byte(signBaseline(b.Target, b.Paths, key)))
+}
+
+// loadBaselineFile reads and signature-checks a -compare-baseline/"capsaicin
+// baseline" JSON file against the HMAC key resolved from keyFile/baselineKeyEnvVar
+func loadBaselineFile(path, keyFile string) (*baselineSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var snapshot baselineSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	key, err := resolveBaselineKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyBaseline(&snapshot, key) {
+		return nil, fmt.Errorf("baseline HMAC does not match its content -- has it been hand-edited, or is the wrong key configured?")
+	}
+	return &snapshot, nil
+}
+
+// baselineDrift is one difference between a scan's findings and the
+// approved baseline, reported by -compare-baseline
+type baselineDrift struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// diffResultsAgainstBaseline compares results against baseline's approved
+// paths, reporting any path that's newly reachable or whose status/content
+// hash changed. It can't detect paths that *disappeared* from the baseline,
+// since a normal scan's wordlist isn't guaranteed to match the one
+// "capsaicin baseline" used to build it. This is distinct from the
+// pre-existing diffAgainstBaseline/-baseline flag, which diffs two prior
+// scans' JSON output for the HTML report rather than a signed inventory
+func diffResultsAgainstBaseline(results []Result, baseline *baselineSnapshot) []baselineDrift {
+	approved := make(map[string]baselineEntry, len(baseline.Paths))
+	for _, p := range baseline.Paths {
+		approved[p.Path] = p
+	}
+
+	seen := make(map[string]bool)
+	var drift []baselineDrift
+	for _, r := range results {
+		path := extractPath(r.URL)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		entry, ok := approved[path]
+		if !ok {
+			drift = append(drift, baselineDrift{Path: path, Reason: fmt.Sprintf("newly reachable (status %d)", r.StatusCode)})
+			continue
+		}
+		if entry.StatusCode != r.StatusCode {
+			drift = append(drift, baselineDrift{Path: path, Reason: fmt.Sprintf("status changed %d -> %d", entry.StatusCode, r.StatusCode)})
+		} else if r.ContentHash != "" && entry.ContentHash != r.ContentHash {
+			drift = append(drift, baselineDrift{Path: path, Reason: "content changed"})
+		}
+	}
+
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Path < drift[j].Path })
+	return drift
+}
+
+// runBaseline implements "capsaicin baseline": a sequential crawl of -u's
+// wordlist producing a signed inventory of publicly reachable paths for
+// defenders to commit and diff later scans against via -compare-baseline
+func runBaseline(args []string) {
+	fs := flag.NewFlagSet("baseline", flag.ExitOnError)
+	targetURL := fs.String("u", "", "Target URL to snapshot")
+	wordlist := fs.String("w", "", "Wordlist of paths to probe")
+	output := fs.String("o", "baseline.json", "Output file for the signed baseline")
+	timeout := fs.Int("timeout", 10, "Request timeout in seconds")
+	keyFile := fs.String("key-file", "", fmt.Sprintf("File holding the hex-encoded HMAC key to sign with (falls back to %s); generated and printed if neither is set -- keep it out of the repo the baseline is committed to", baselineKeyEnvVar))
+	fs.Parse(args)
+
+	if *targetURL == "" || *wordlist == "" {
+		fmt.Printf("%s[ERROR]%s baseline requires -u and -w\n", ColorRed+ColorBold, ColorReset)
+		os.Exit(1)
+	}
+
+	words, err := loadWordlist(*wordlist)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to load wordlist: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+
+	client := &http.Client{Timeout: time.Duration(*timeout) * time.Second}
+	userAgent := getRandomUserAgent()
+
+	var paths []baselineEntry
+	for _, word := range words {
+		target := buildURL(*targetURL, word)
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			continue
+		}
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := client.Do(req)
+		if err != nil {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == 404 {
+			continue
+		}
+
+		headers := make(map[string]string)
+		for _, name := range baselineHeaderNames {
+			if v := resp.Header.Get(name); v != "" {
+				headers[name] = v
+			}
+		}
+
+		paths = append(paths, baselineEntry{
+			Path:        extractPath(target),
+			StatusCode:  resp.StatusCode,
+			ContentHash: sha256Hex(body),
+			Headers:     headers,
+		})
+	}
+
+	sort.Slice(paths, func(i, j int) bool { return paths[i].Path < paths[j].Path })
+
+	key, err := resolveBaselineKey(*keyFile)
+	if err != nil {
+		key = make([]byte, 32)
+		crand.Read(key)
+		fmt.Printf("%s[WARN]%s No baseline HMAC key configured -- generated one. Save it somewhere that does NOT get committed alongside %s, and set %s (or -key-file on \"capsaicin baseline\"/-compare-baseline) to it:\n%s\n",
+			ColorYellow, ColorReset, *output, baselineKeyEnvVar, hex.EncodeToString(key))
+	}
+
+	snapshot := baselineSnapshot{
+		Target:      *targetURL,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Paths:       paths,
+	}
+	snapshot.HMAC = signBaseline(snapshot.Target, snapshot.Paths, key)
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to marshal baseline: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to write baseline: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s[✓]%s Wrote baseline of %d reachable path(s) for %s to %s\n", ColorNeonGreen, ColorReset, len(paths), *targetURL, *output)
+}
+
+// tokenBucket is a simple requests-per-second limiter: tokens refill
+// continuously at rate/sec up to capacity, and wait() blocks until one is
+// available, so stealth scans can stay under a WAF's detection threshold
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+func newTokenBucket(ratePerSecond float64) *tokenBucket {
+	return &tokenBucket{tokens: ratePerSecond, capacity: ratePerSecond, rate: ratePerSecond, last: time.Now()}
+}
+
+func (b *tokenBucket) wait() {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+		sleepFor := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		time.Sleep(sleepFor)
+	}
+}
+
+// globalRateLimiter enforces -rate across the whole scan; targetRateLimiters
+// holds one lazily-created *tokenBucket per target for -rate-per-target
+var (
+	globalRateLimiter  *tokenBucket
+	targetRateLimiters sync.Map // map[string]*tokenBucket
+)
+
+// targetThrottledUntil and targetBlockStreak back the queue rebalancer: a
+// target that racks up consecutive 429s or WAF-blocked 403s gets marked
+// throttled for a cooldown window, so workers stop burning capacity retrying
+// it and shift onto other targets' queued tasks instead
+var (
+	targetThrottledUntil sync.Map // map[string]time.Time
+	targetBlockStreak    sync.Map // map[string]*int64
+)
+
+const (
+	targetThrottleStreak   = 5
+	targetThrottleCooldown = 20 * time.Second
+)
+
+// confirmed404Words records, per target+word, that the bare word already came
+// back matching the target's calibrated 404 signature. Extension-variant
+// tasks for that same word are then skipped as they're dequeued rather than
+// fired, since a genuine 404 family almost never resolves differently just
+// because of an appended extension.
+var confirmed404Words sync.Map // map[string]bool, keyed by target+"|"+word
+
+func word404Key(target, word string) string {
+	return target + "|" + word
+}
+
+// dirSignatures holds a recalibrated []ResponseSignature per directory
+// discovered during recursion, keyed by dirSignatureKey(target, dirPath), so
+// a nested app with its own 404 behavior gets its own signature set instead
+// of inheriting a possibly-mismatched one from the scan root
+var dirSignatures sync.Map // map[string][]ResponseSignature
+
+func dirSignatureKey(target, dirPath string) string {
+	return target + "|" + dirPath
+}
+
+// signaturesFor returns the target's root signatures merged with the most
+// specific recalibrated directory signatures available for path, if any
+func signaturesFor(target, path string, rootSignatures []ResponseSignature) []ResponseSignature {
+	dir := path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir = path[:i]
+	}
+	if dir == "" {
+		return rootSignatures
+	}
+	sigs, ok := dirSignatures.Load(dirSignatureKey(target, dir))
+	if !ok {
+		return rootSignatures
+	}
+	return append(append([]ResponseSignature{}, rootSignatures...), sigs.([]ResponseSignature)...)
+}
+
+// spaTargets records, per target, that -spa-aware calibration recognized a
+// single-page app (every calibration probe returned the same 200 page), so
+// the task feeder can restrict that target's wordlist to -spa-prefixes
+var spaTargets sync.Map // map[string]bool
+
+// isSPASignature reports whether a target's calibration signatures look like
+// a single-page app that serves the same 200 document for any unknown path,
+// rather than a genuine per-path 404
+func isSPASignature(signatures []ResponseSignature) bool {
+	if len(signatures) < 2 {
+		return false
+	}
+	first := signatures[0]
+	if first.StatusCode != http.StatusOK {
+		return false
+	}
+	for _, sig := range signatures[1:] {
+		if sig.StatusCode != http.StatusOK || sig.Size != first.Size || sig.WordCount != first.WordCount {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSPAPrefix reports whether path starts with one of the operator's
+// -spa-prefixes, after stripping any leading slash
+func hasSPAPrefix(path string, prefixes []string) bool {
+	trimmed := strings.TrimPrefix(path, "/")
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(trimmed, strings.TrimPrefix(prefix, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTargetBlockOutcome tracks consecutive blocked/rate-limited responses
+// per target, throttling the target once the streak crosses
+// targetThrottleStreak and clearing the streak on any non-blocked response.
+// Returns true the moment the target newly crosses into a throttled state,
+// so the caller can surface a one-time ban alert.
+func recordTargetBlockOutcome(target string, blocked bool) bool {
+	if !blocked {
+		targetBlockStreak.Delete(target)
+		return false
+	}
+
+	counter, _ := targetBlockStreak.LoadOrStore(target, new(int64))
+	if atomic.AddInt64(counter.(*int64), 1) >= targetThrottleStreak {
+		targetThrottledUntil.Store(target, time.Now().Add(targetThrottleCooldown))
+		targetBlockStreak.Delete(target)
+		return true
+	}
+	return false
+}
+
+// targetBanSignature holds the fuzzy content hash of the most recent
+// blocked (403/406) response seen per target, so a ban streak only builds
+// when consecutive hits are the *same* templated challenge/block page
+// rather than unrelated one-off 403s on individual paths
+var targetBanSignature sync.Map // map[string]uint64
+
+func isBanStatus(statusCode int) bool {
+	return statusCode == 403 || statusCode == 406
+}
+
+// isUniformBanPage reports whether statusCode/simHash match the target's
+// previously recorded block-page signature (within soft404SimHashThreshold),
+// indicating a WAF ban page rather than a genuine per-path 403/406, and
+// records the current response as the new signature to compare future hits
+// against
+func isUniformBanPage(target string, statusCode int, simHash uint64) bool {
+	if !isBanStatus(statusCode) {
+		targetBanSignature.Delete(target)
+		return false
+	}
+
+	uniform := false
+	if prev, ok := targetBanSignature.Load(target); ok {
+		if bits.OnesCount64(prev.(uint64)^simHash) <= soft404SimHashThreshold {
+			uniform = true
+		}
+	}
+	targetBanSignature.Store(target, simHash)
+	return uniform
+}
+
+// targetRateLimitStreak tracks consecutive 429/503 responses per target, for
+// recordRateLimitBackoff's exponential delay; reset on any other status code
+var targetRateLimitStreak sync.Map // map[string]*int64
+
+const (
+	rateLimitBackoffBase = 2 * time.Second
+	rateLimitBackoffMax  = 5 * time.Minute
+)
+
+// parseRetryAfter parses a Retry-After header value in either the
+// delay-seconds or HTTP-date form (RFC 7231 7.1.3), returning false if value
+// is empty or unparsable
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(time.Until(when), 0), true
+	}
+	return 0, false
+}
+
+// recordRateLimitBackoff marks target throttled on a 429/503 response,
+// doubling the cooldown on each consecutive hit (capped at
+// rateLimitBackoffMax) and honoring the server's Retry-After value whenever
+// it asks for longer than the computed exponential delay. Any non-429/503
+// response clears the streak via clearRateLimitStreak.
+func recordRateLimitBackoff(target string, retryAfter time.Duration) time.Duration {
+	counter, _ := targetRateLimitStreak.LoadOrStore(target, new(int64))
+	streak := atomic.AddInt64(counter.(*int64), 1)
+
+	backoff := min(rateLimitBackoffBase*time.Duration(int64(1)<<min(streak-1, 8)), rateLimitBackoffMax)
+	backoff = max(backoff, retryAfter)
+
+	targetThrottledUntil.Store(target, time.Now().Add(backoff))
+	return backoff
+}
+
+func clearRateLimitStreak(target string) {
+	targetRateLimitStreak.Delete(target)
+}
+
+// wordProgress tracks, per target, the furthest wordlist index reached so
+// far, so operators can see "this target is 80% through raft-large" and know
+// exactly where a scan could be resumed from
+var wordProgress sync.Map // map[string]int64
+
+// recordWordProgress advances the stored wordlist position for a target if
+// idx is further along than what has already been recorded
+func recordWordProgress(target string, idx int) {
+	if idx < 0 {
+		return
+	}
+	for {
+		existing, loaded := wordProgress.LoadOrStore(target, int64(idx))
+		if !loaded {
+			return
+		}
+		current := existing.(int64)
+		if int64(idx) <= current {
+			return
+		}
+		if wordProgress.CompareAndSwap(target, current, int64(idx)) {
+			return
+		}
+	}
+}
+
+// wordProgressPercent returns how far through a wordlist of the given size a
+// target has reached, as a percentage
+func wordProgressPercent(target string, wordlistSize int) float64 {
+	if wordlistSize == 0 {
+		return 0
+	}
+	v, ok := wordProgress.Load(target)
+	if !ok {
+		return 0
+	}
+	return float64(v.(int64)+1) / float64(wordlistSize) * 100
 }
 
 // SecretPattern holds regex patterns for secret detection
@@ -109,6 +1124,20 @@ type WAFSignature struct {
 	ServerHeader  string
 	CustomHeader  string
 	CookiePattern string
+	BodyRegex     string `json:"BodyRegex,omitempty"`
+	StatusCode    int    `json:"StatusCode,omitempty"`
+
+	// bodyRegexCompiled is derived from BodyRegex at load time for
+	// signatures read from -waf-signatures; built-in signatures leave it
+	// nil since they don't use body matching
+	bodyRegexCompiled *regexp.Regexp
+}
+
+// TagRule holds a lightweight classification rule: tag a result when its
+// response body contains a given substring
+type TagRule struct {
+	Name     string
+	Contains string
 }
 
 // Implement flag.Value interface for header flags - GLOBAL SCOPE FIX
@@ -123,6 +1152,18 @@ func (h *headerFlags) Set(value string) error {
 	return nil
 }
 
+// Implement flag.Value interface for tag rule flags
+type tagFlags []string
+
+func (t *tagFlags) String() string {
+	return strings.Join(*t, ", ")
+}
+
+func (t *tagFlags) Set(value string) error {
+	*t = append(*t, value)
+	return nil
+}
+
 // Modern User-Agent pool for rotation (anti-fingerprinting)
 var userAgents = []string{
 	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
@@ -140,7 +1181,41 @@ var userAgents = []string{
 // HTTP Client with custom transport for performance
 var httpClient *http.Client
 
+// calibrationHTTPClient and heavyHTTPClient carry distinct timeouts from the
+// main fuzzing client, set via -calibration-timeout and -heavy-timeout: a
+// calibration probe should fail fast so a slow target doesn't stall startup,
+// while heavy follow-up requests (live secret validation) can tolerate a
+// longer timeout than a one-size-fits-all -timeout would otherwise force
+var calibrationHTTPClient *http.Client
+var heavyHTTPClient *http.Client
+
+// proxyEntry tracks one member of a -proxies pool: its address and the
+// health-check outcome used to route requests away from dead proxies and
+// back onto recovered ones, rather than letting one dead proxy silently
+// fail every request routed through it
+type proxyEntry struct {
+	URL      *url.URL
+	Healthy  int32 // atomic bool: 1=healthy, 0=unhealthy
+	Checks   int64
+	Failures int64
+}
+
+var proxyPool []*proxyEntry
+var proxyRRIndex int64
+
+// Audit log for aggressive-module usage (canary safety)
+var (
+	auditFile  *os.File
+	auditMutex sync.Mutex
+)
+
 // SENSORS: Secret detection patterns
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)
+var slackTokenPattern = regexp.MustCompile(`xox[baprs]-[0-9]{10,13}-[0-9]{10,13}-[a-zA-Z0-9]{24,}`)
+var googleAPIKeyPattern = regexp.MustCompile(`AIza[0-9A-Za-z_-]{35}`)
+var awsSecretKeyPattern = regexp.MustCompile(`(?i)aws_secret_access_key["\s:=]+([A-Za-z0-9/+=]{40})`)
+
 var secretPatterns = []SecretPattern{
 	{
 		Name:    "AWS Access Key",
@@ -156,48 +1231,221 @@ var secretPatterns = []SecretPattern{
 	},
 	{
 		Name:    "JWT Token",
-		Pattern: regexp.MustCompile(`eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+		Pattern: jwtPattern,
 	},
 	{
 		Name:    "Slack Token",
-		Pattern: regexp.MustCompile(`xox[baprs]-[0-9]{10,13}-[0-9]{10,13}-[a-zA-Z0-9]{24,}`),
+		Pattern: slackTokenPattern,
 	},
 	{
 		Name:    "Google API Key",
-		Pattern: regexp.MustCompile(`AIza[0-9A-Za-z_-]{35}`),
+		Pattern: googleAPIKeyPattern,
 	},
 }
 
-// WAF Detection Signatures
-var wafSignatures = []WAFSignature{
-	{
-		Name:          "Cloudflare",
-		ServerHeader:  "cloudflare",
-		CookiePattern: "__cfduid",
-	},
-	{
-		Name:         "AWS WAF",
-		CustomHeader: "X-Amz-Cf-Id",
-	},
-	{
-		Name:         "Akamai",
-		ServerHeader: "AkamaiGHost",
-	},
+// PII detection patterns - a separate finding class from credentials/secrets,
+// since "this page leaks emails" and "this page leaks an AWS key" call for
+// different severity and remediation
+var piiPatterns = []SecretPattern{
 	{
-		Name:         "Imperva",
-		CustomHeader: "X-Iinfo",
+		Name:    "Email Address",
+		Pattern: regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`),
 	},
 	{
-		Name:          "F5 BigIP",
-		CookiePattern: "BIGipServer",
+		Name:    "Phone Number",
+		Pattern: regexp.MustCompile(`\b(?:\+?1[-.\s]?)?\(?\d{3}\)?[-.\s]\d{3}[-.\s]\d{4}\b`),
 	},
 	{
-		Name:         "Sucuri",
-		ServerHeader: "Sucuri",
+		Name:    "National ID (SSN)",
+		Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
 	},
-	{
-		Name:         "StackPath",
-		ServerHeader: "StackPath",
+}
+
+var creditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){13,19}\b`)
+
+// luhnValid checks a digit string against the Luhn checksum used by major
+// card schemes, to separate real-looking card numbers from random digit runs
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return len(digits) >= 13 && sum%10 == 0
+}
+
+// detectPII scans content for email, phone, national ID and (Luhn-validated)
+// credit card numbers, returning the distinct PII types found
+func detectPII(content string) []string {
+	var found []string
+	seen := make(map[string]bool)
+
+	for _, pattern := range piiPatterns {
+		if pattern.Pattern.MatchString(content) && !seen[pattern.Name] {
+			found = append(found, pattern.Name)
+			seen[pattern.Name] = true
+		}
+	}
+
+	if !seen["Credit Card"] {
+		for _, match := range creditCardPattern.FindAllString(content, -1) {
+			digits := strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, match)
+			if luhnValid(digits) {
+				found = append(found, "Credit Card")
+				seen["Credit Card"] = true
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+// harvestEntry is one target's deduplicated -harvest-file output: emails and
+// name-like patterns collected across every page scanned on that target,
+// kept entirely separate from the main findings table since harvested
+// contacts aren't themselves a security finding
+type harvestEntry struct {
+	Target string   `json:"target"`
+	Emails []string `json:"emails,omitempty"`
+	Names  []string `json:"names,omitempty"`
+}
+
+var (
+	harvestMu    sync.Mutex
+	harvestStore = make(map[string]*harvestEntry)
+	harvestSeen  = make(map[string]map[string]bool) // target -> "email:x"/"name:x" -> seen
+)
+
+var harvestEmailPattern = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+
+// harvestNamePattern is a deliberately loose "Firstname Lastname" heuristic
+// (two consecutive capitalized words) -- expect false positives from any
+// Title Case phrase; -harvest-file output is recon raw material to be
+// triaged by hand, not a verified finding
+var harvestNamePattern = regexp.MustCompile(`\b[A-Z][a-z]{1,20} [A-Z][a-z]{1,20}\b`)
+
+// harvestFromBody extracts emails and name-like patterns from a scanned
+// page's body and records any not already seen for target, for later
+// -harvest-file output
+func harvestFromBody(target, content string) {
+	harvestMu.Lock()
+	defer harvestMu.Unlock()
+
+	seen, ok := harvestSeen[target]
+	if !ok {
+		seen = make(map[string]bool)
+		harvestSeen[target] = seen
+	}
+	entry, ok := harvestStore[target]
+	if !ok {
+		entry = &harvestEntry{Target: target}
+		harvestStore[target] = entry
+	}
+
+	for _, email := range harvestEmailPattern.FindAllString(content, -1) {
+		if key := "email:" + email; !seen[key] {
+			seen[key] = true
+			entry.Emails = append(entry.Emails, email)
+		}
+	}
+	for _, name := range harvestNamePattern.FindAllString(content, -1) {
+		if key := "name:" + name; !seen[key] {
+			seen[key] = true
+			entry.Names = append(entry.Names, name)
+		}
+	}
+}
+
+// saveHarvest writes every target's deduplicated harvest as indented JSON,
+// skipping targets where nothing was collected
+func saveHarvest(filename string) error {
+	harvestMu.Lock()
+	defer harvestMu.Unlock()
+
+	entries := make([]harvestEntry, 0, len(harvestStore))
+	for _, entry := range harvestStore {
+		if len(entry.Emails) > 0 || len(entry.Names) > 0 {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Target < entries[j].Target })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+var internalHostnamePattern = regexp.MustCompile(`\b[a-zA-Z0-9][a-zA-Z0-9-]*\.(?:local|corp|internal|intranet|lan)\b`)
+var uncPathPattern = regexp.MustCompile(`\\\\[a-zA-Z0-9_.$-]+\\[^\s"'<>)]+`)
+
+// detectInfoDisclosure scans a response body for internal infrastructure
+// leakage -- RFC1918 addresses, internal-only hostname suffixes (.local,
+// .corp, .internal, .intranet, .lan) and Windows UNC paths -- returning the
+// distinct disclosure types found. This mirrors detectPII/detectSecrets but
+// is reported as its own "information disclosure" finding class, since none
+// of these are sensitive on their own, only as infrastructure fingerprints.
+func detectInfoDisclosure(content string) []string {
+	var found []string
+
+	if privateIPPattern.MatchString(content) {
+		found = append(found, "Internal IP Address")
+	}
+	if internalHostnamePattern.MatchString(content) {
+		found = append(found, "Internal Hostname")
+	}
+	if uncPathPattern.MatchString(content) {
+		found = append(found, "UNC Path")
+	}
+
+	return found
+}
+
+// WAF Detection Signatures
+var wafSignatures = []WAFSignature{
+	{
+		Name:          "Cloudflare",
+		ServerHeader:  "cloudflare",
+		CookiePattern: "__cfduid",
+	},
+	{
+		Name:         "AWS WAF",
+		CustomHeader: "X-Amz-Cf-Id",
+	},
+	{
+		Name:         "Akamai",
+		ServerHeader: "AkamaiGHost",
+	},
+	{
+		Name:         "Imperva",
+		CustomHeader: "X-Iinfo",
+	},
+	{
+		Name:          "F5 BigIP",
+		CookiePattern: "BIGipServer",
+	},
+	{
+		Name:         "Sucuri",
+		ServerHeader: "Sucuri",
+	},
+	{
+		Name:         "StackPath",
+		ServerHeader: "StackPath",
 	},
 	{
 		Name:         "Wordfence",
@@ -205,12 +1453,146 @@ var wafSignatures = []WAFSignature{
 	},
 }
 
+// runInDocker implements -in-docker: it re-execs the current scan inside an
+// ephemeral, network-namespaced container via the docker CLI (shelled out
+// to rather than linked against, to keep this a zero-dependency binary), so
+// operators can guarantee scan traffic only leaves through -docker-network
+// (e.g. "container:<vpn-sidecar>"). Results land back on the host via a
+// bind-mounted output directory rather than anything parsed from stdout
+func runInDocker(config Config) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fmt.Errorf("docker not found in PATH: %w", err)
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not resolve own executable path: %w", err)
+	}
+
+	outDir, err := os.MkdirTemp("", "capsaicin-docker-out")
+	if err != nil {
+		return fmt.Errorf("could not create output dir: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	const containerOutput = "/out/results.json"
+	innerArgs := stripFlag(os.Args[1:], "-in-docker")
+	innerArgs = append(innerArgs, "-o", containerOutput)
+
+	dockerArgs := []string{
+		"run", "--rm",
+		"--network", config.DockerNetwork,
+		"-v", exePath + ":/capsaicin:ro",
+		"-v", outDir + ":/out",
+		config.DockerImage,
+		"/capsaicin",
+	}
+	dockerArgs = append(dockerArgs, innerArgs...)
+
+	fmt.Printf("%s[DOCKER]%s Running scan in %s (network=%s)\n", ColorNeonCyan, ColorReset, config.DockerImage, config.DockerNetwork)
+
+	cmd := exec.Command("docker", dockerArgs...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker run: %w", err)
+	}
+
+	containerResults := outDir + "/results.json"
+	if _, err := os.Stat(containerResults); err != nil {
+		return fmt.Errorf("container exited but produced no results at %s: %w", containerOutput, err)
+	}
+
+	dest := config.OutputFile
+	if dest == "" {
+		dest = "results.json"
+	}
+	data, err := os.ReadFile(containerResults)
+	if err != nil {
+		return fmt.Errorf("reading container results: %w", err)
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", dest, err)
+	}
+
+	fmt.Printf("%s[✓]%s Containerized scan complete; results retrieved to: %s\n", ColorNeonGreen, ColorReset, dest)
+	return nil
+}
+
+// stripFlag removes a boolean flag (and, if present, its "=value" form)
+// from an argument list, used to avoid re-triggering -in-docker inside the
+// container it spawns
+func stripFlag(args []string, name string) []string {
+	filtered := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			continue
+		}
+		filtered = append(filtered, arg)
+	}
+	return filtered
+}
+
 func main() {
-	showBanner()
+	enableColorConsole()
+
+	// NDJSON pipe protocol: "capsaicin analyze -" applies downstream modules
+	// to findings streamed from another capsaicin instance's "-stream" output
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		runAnalyze(os.Args[2:])
+		return
+	}
+
+	// "capsaicin baseline -u ... -w ..." produces a signed inventory of
+	// publicly reachable paths for defenders to commit and diff later
+	// scans against via -compare-baseline
+	if len(os.Args) > 1 && os.Args[1] == "baseline" {
+		runBaseline(os.Args[2:])
+		return
+	}
+
+	// "capsaicin serve" runs as a long-lived service: a REST API to start,
+	// poll, stream (SSE), and cancel scans, for other tooling or a web UI
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+
+	// "capsaicin controller" shards a wordlist across "capsaicin worker"
+	// processes over a minimal HTTP task queue, for distributed scanning
+	// across very large scopes
+	if len(os.Args) > 1 && os.Args[1] == "controller" {
+		runController(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		runWorker(os.Args[2:])
+		return
+	}
+
+	// "capsaicin annotate" enriches a stored scan's results file in place
+	// with analyst notes/tags, keeping triage state inside the toolchain
+	if len(os.Args) > 1 && os.Args[1] == "annotate" {
+		runAnnotate(os.Args[2:])
+		return
+	}
 
 	// Parse command line flags
 	config := parseFlags()
 
+	if config.InDocker {
+		if err := runInDocker(config); err != nil {
+			fmt.Printf("%s[ERROR]%s -in-docker run failed: %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if !config.Stream {
+		showBanner()
+	}
+
 	// Check for STDIN input (multi-target mode)
 	targets := []string{}
 	stat, _ := os.Stdin.Stat()
@@ -231,27 +1613,109 @@ func main() {
 		os.Exit(1)
 	}
 
+	if len(config.PortsPreflight) > 0 {
+		fmt.Printf("%s[*]%s Probing %d port(s) per bare-host target before fuzzing...\n", ColorNeonCyan, ColorReset, len(config.PortsPreflight))
+		targets = expandPortsPreflight(targets, config.PortsPreflight, time.Duration(config.Timeout)*time.Second)
+		fmt.Printf("%s[✓]%s Expanded to %d target(s)\n", ColorNeonGreen, ColorReset, len(targets))
+	}
+
 	// Validate configuration
 	if err := validateConfig(&config, targets); err != nil {
 		fmt.Printf("%s[ERROR]%s %s\n", ColorRed+ColorBold, ColorReset, err)
 		os.Exit(1)
 	}
 
+	if config.LocalMode {
+		if bad := firstNonPrivateTarget(targets); bad != "" {
+			fmt.Printf("%s[ERROR]%s -local requires every target to be loopback/private -- %s isn't, refusing to disable stealth/WAF logic against it\n", ColorRed+ColorBold, ColorReset, bad)
+			os.Exit(1)
+		}
+		localModeActive.Store(true)
+		config.Jitter = 0
+		if config.Threads <= 50 {
+			config.Threads = 200
+		}
+		fmt.Printf("%s[✓]%s -local: loopback/private targets confirmed -- UA rotation, jitter, and WAF detection disabled, threads raised to %d\n",
+			ColorNeonGreen, ColorReset, config.Threads)
+	}
+
+	// Canary safety check: aggressive modules (bypass/mutation/method-fuzz)
+	// require either -aggressive or explicit interactive confirmation
+	if !config.Aggressive {
+		config.Aggressive = confirmAggressive(targets)
+	}
+	if config.Aggressive {
+		if err := openAuditLog(); err != nil {
+			fmt.Printf("%s[WARN]%s Could not open audit log: %s\n", ColorYellow, ColorReset, err)
+		} else {
+			defer auditFile.Close()
+		}
+	}
+
+	if len(config.ProxyURLs) > 0 {
+		proxyPool = initProxyPool(config.ProxyURLs)
+		go watchProxyHealth(proxyPool, config.ProxyHealthInterval)
+	}
+
+	if config.WAFSignaturesFile != "" {
+		if err := loadWAFSignatures(config.WAFSignaturesFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to load -waf-signatures: %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+	}
+
+	tlsConfig, err := buildTLSConfig(config)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+
 	// Initialize HTTP client with timeout
+	sharedTransport := &http.Transport{
+		MaxIdleConns:        config.Threads * 2,
+		MaxIdleConnsPerHost: config.Threads,
+		IdleConnTimeout:     30 * time.Second,
+		DialContext:         dialContextFor(config),
+		TLSClientConfig:     tlsConfig,
+		ForceAttemptHTTP2:   config.HTTP2,
+	}
+	noRedirect := func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
 	httpClient = &http.Client{
-		Timeout: time.Duration(config.Timeout) * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        config.Threads * 2,
-			MaxIdleConnsPerHost: config.Threads,
-			IdleConnTimeout:     30 * time.Second,
-		},
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+		Timeout:       time.Duration(config.Timeout) * time.Second,
+		Transport:     sharedTransport,
+		CheckRedirect: noRedirect,
+	}
+	calibrationHTTPClient = &http.Client{
+		Timeout:       time.Duration(config.CalibrationTimeout) * time.Second,
+		Transport:     sharedTransport,
+		CheckRedirect: noRedirect,
+	}
+	heavyHTTPClient = &http.Client{
+		Timeout:       time.Duration(config.HeavyTimeout) * time.Second,
+		Transport:     sharedTransport,
+		CheckRedirect: noRedirect,
+	}
+
+	if config.LoginConfigFile != "" {
+		cfg, err := parseLoginConfigFile(config.LoginConfigFile)
+		if err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to load -login-config: %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+		activeLoginConfig = cfg
+		if err := refreshLogin(config); err != nil {
+			fmt.Printf("%s[ERROR]%s Login failed: %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s[✓]%s Logged in via -login-config, injecting %s on every request\n", ColorNeonGreen, ColorReset, cfg.TokenHeader)
 	}
 
 	// Display Attack Configuration
-	showAttackConfig(config, targets)
+	if !config.Stream {
+		showAttackConfig(config, targets)
+	}
 
 	// Load wordlist
 	words, err := loadWordlist(config.Wordlist)
@@ -260,6 +1724,59 @@ func main() {
 		os.Exit(1)
 	}
 
+	// DNS brute-force mode is a distinct workflow from path fuzzing: it
+	// resolves wordlist entries as subdomains instead of requesting them as
+	// paths, so it runs to completion and exits rather than falling through
+	// into the worker pool below
+	if config.DNSMode {
+		var total int
+		for _, target := range targets {
+			domain := target
+			if parsed, err := url.Parse(target); err == nil && parsed.Hostname() != "" {
+				domain = parsed.Hostname()
+			}
+			found := runDNSBruteForce(config, domain, words)
+			total += len(found)
+		}
+		fmt.Printf("\n%s[✓]%s DNS brute-force complete: %d subdomain(s) found\n", ColorNeonGreen, ColorReset, total)
+		return
+	}
+
+	// Vhost mode is also a distinct workflow: it fuzzes the Host header
+	// against -u (typically a bare IP) to discover virtual hosts, then
+	// path-fuzzes each one with the same wordlist, so it produces its own
+	// result set and runs through the normal report pipeline instead of the
+	// path-fuzzing worker pool below
+	if config.VhostMode {
+		var vhostResults []Result
+		for _, target := range targets {
+			domain := config.VhostDomain
+			if domain == "" {
+				if parsed, err := url.Parse(target); err == nil && parsed.Hostname() != "" {
+					domain = parsed.Hostname()
+				}
+			}
+			vhostResults = append(vhostResults, runVhostScan(config, target, domain, words)...)
+		}
+		vhostStats := &Stats{
+			Total:     int64(len(vhostResults)),
+			Processed: int64(len(vhostResults)),
+			Found:     int64(len(vhostResults)),
+			StartTime: time.Now(),
+		}
+		finishScan(vhostResults, vhostStats, config, time.Since(vhostStats.StartTime), false)
+		return
+	}
+
+	var knownURLs map[string]bool
+	if config.ExcludeKnownFile != "" {
+		knownURLs, err = loadKnownURLs(config.ExcludeKnownFile)
+		if err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to load -exclude-known file: %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+	}
+
 	// Initialize statistics
 	initialTaskCount := int64(len(targets) * len(words) * (1 + len(config.Extensions)))
 	stats := &Stats{
@@ -276,24 +1793,63 @@ func main() {
 	var dirMutex sync.Mutex
 
 	// Start fuzzing engine
-	fmt.Printf("\n%s╔════════════════════════════════════════════════════════════════╗%s\n", ColorMagenta+ColorBold, ColorReset)
-	fmt.Printf("%s║                     🔥 ATTACK INITIATED 🔥                     ║%s\n", ColorMagenta+ColorBold, ColorReset)
-	fmt.Printf("%s╚════════════════════════════════════════════════════════════════╝%s\n\n", ColorMagenta+ColorBold, ColorReset)
+	if !config.Stream {
+		fmt.Printf("\n%s╔════════════════════════════════════════════════════════════════╗%s\n", ColorMagenta+ColorBold, ColorReset)
+		fmt.Printf("%s║                     🔥 ATTACK INITIATED 🔥                     ║%s\n", ColorMagenta+ColorBold, ColorReset)
+		fmt.Printf("%s╚════════════════════════════════════════════════════════════════╝%s\n\n", ColorMagenta+ColorBold, ColorReset)
+	}
 
 	// Worker pool pattern with channels
 	taskChan := make(chan Task, config.Threads*2)
 	resultChan := make(chan Result, config.Threads*2)
 	newTaskChan := make(chan Task, config.Threads*2)
+	retryChan := make(chan Task, config.Threads*4)
+	var timeoutChan chan Task
+	if config.RetryTimeouts {
+		timeoutChan = make(chan Task, config.Threads*4)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Progress reporter goroutine
-	if !config.Verbose {
+	if config.TUI {
+		go tuiDashboard(stats, targets, ctx)
+	} else if !config.Verbose && !config.Stream {
 		go progressReporter(stats, ctx)
 	}
 
+	// Signal-driven runtime stats dump (SIGUSR1) for diagnosing long-running scans
+	go watchStatsSignal(stats, taskChan, resultChan, targets)
+
+	// Long-run health telemetry: a scrapeable /metrics endpoint and/or a
+	// periodic stderr snapshot, so multi-day monitor-mode runs can be
+	// trusted not to be leaking goroutines or silently stuck
+	if config.MetricsAddr != "" {
+		go startMetricsServer(config.MetricsAddr, stats, taskChan, resultChan)
+	}
+	if config.HealthInterval > 0 {
+		go watchHealth(stats, taskChan, resultChan, config.HealthInterval)
+	}
+
+	// Graceful abort: SIGINT/SIGTERM writes whatever results exist so far
+	// instead of losing the scan outright
+	go watchInterrupt(&results, &resultsMutex, stats, config)
+
+	// Runtime keyboard hotkeys (-keyboard-controls): p pause/resume, +/-
+	// adjust active thread count, s stats snapshot, q finish gracefully
+	if config.KeyboardControls {
+		activeThreadCap.Store(int64(config.Threads))
+		go watchKeyboardControls(stats, taskChan, resultChan, &results, &resultsMutex, config, targets)
+	}
+
 	// Result collector goroutine
 	var wg sync.WaitGroup
+	ndjsonEncoder := json.NewEncoder(os.Stdout)
+	var grouped *groupedPrinter
+	if config.GroupOutput && !config.Stream {
+		grouped = newGroupedPrinter()
+		go grouped.run(ctx, config.GroupFlush)
+	}
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -302,12 +1858,48 @@ func main() {
 			results = append(results, result)
 			resultsMutex.Unlock()
 
-			if !config.Verbose {
+			if result.Critical {
+				notifyWaitGroup.Add(1)
+				go notifyCriticalFinding(config, result)
+			}
+
+			if result.ResponseTimeMs > 0 {
+				recordLatencySample(result.ResponseTimeMs)
+			}
+
+			if config.Stream {
+				ndjsonEncoder.Encode(result)
+			} else if config.TUI {
+				recordTUIFinding(result)
+			} else if grouped != nil {
+				grouped.add(result)
+			} else if !config.Verbose {
 				printResult(result)
 			}
 		}
 	}()
 
+	// Throttle-aware queue rebalancer: a target that's being actively
+	// blocked/rate-limited gets its tasks held here and re-fed to taskChan
+	// once its cooldown expires, instead of workers burning capacity
+	// retrying a target that's only going to keep failing
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for task := range retryChan {
+			if until, ok := targetThrottledUntil.Load(task.TargetURL); ok {
+				if wait := time.Until(until.(time.Time)); wait > 0 {
+					time.Sleep(wait)
+				}
+			}
+			taskChan <- task
+		}
+	}()
+
+	if config.RateLimit > 0 {
+		globalRateLimiter = newTokenBucket(config.RateLimit)
+	}
+
 	// Recursive task manager goroutine
 	if config.MaxDepth > 0 {
 		wg.Add(1)
@@ -322,20 +1914,41 @@ func main() {
 					scannedDirs[newTask.TargetURL][newTask.Path] = true
 					dirMutex.Unlock()
 
-					for _, word := range words {
-						task := Task{
-							TargetURL: newTask.TargetURL,
-							Path:      strings.TrimSuffix(newTask.Path, "/") + "/" + word,
-							Depth:     newTask.Depth,
+					// Nested apps often 404 differently than the root (a
+					// different framework, a different custom error page),
+					// so recalibrate against this specific directory instead
+					// of relying solely on the root's signatures
+					dirSigs := performCalibration(buildURL(newTask.TargetURL, newTask.Path), config)
+					dirSignatures.Store(dirSignatureKey(newTask.TargetURL, newTask.Path), dirSigs)
+
+					for i, word := range words {
+						path := strings.TrimSuffix(newTask.Path, "/") + "/" + word
+						if !knownURLs[buildURL(newTask.TargetURL, path)] {
+							task := Task{
+								TargetURL: newTask.TargetURL,
+								Path:      path,
+								Depth:     newTask.Depth,
+								WordIndex: i,
+								Word:      word,
+								Source:    "recursion",
+								IsBase:    true,
+							}
+							taskChan <- task
+							atomic.AddInt64(&stats.Total, 1)
 						}
-						taskChan <- task
-						atomic.AddInt64(&stats.Total, 1)
 
 						for _, ext := range config.Extensions {
+							pathWithExt := strings.TrimSuffix(newTask.Path, "/") + "/" + joinWordExt(word, ext)
+							if knownURLs[buildURL(newTask.TargetURL, pathWithExt)] {
+								continue
+							}
 							taskWithExt := Task{
 								TargetURL: newTask.TargetURL,
-								Path:      strings.TrimSuffix(newTask.Path, "/") + "/" + word + ext,
+								Path:      pathWithExt,
 								Depth:     newTask.Depth,
+								WordIndex: i,
+								Word:      word,
+								Source:    "recursion",
 							}
 							taskChan <- taskWithExt
 							atomic.AddInt64(&stats.Total, 1)
@@ -348,24 +1961,67 @@ func main() {
 		}()
 	}
 
-	// Spawn worker pool
+	// Spawn worker pool, optionally ramping up gradually to avoid tripping
+	// burst-based detection and to let adaptive throttling settle in
 	var workerWG sync.WaitGroup
+	rampStep := time.Duration(0)
+	if config.RampUp > 0 && config.Threads > 1 {
+		rampStep = config.RampUp / time.Duration(config.Threads)
+	}
 	for i := 0; i < config.Threads; i++ {
 		workerWG.Add(1)
-		go worker(i, config, taskChan, resultChan, newTaskChan, stats, &workerWG, &scannedDirs, &dirMutex, targets, words)
+		delay := rampStep * time.Duration(i)
+		go func(id int, delay time.Duration) {
+			if delay > 0 {
+				time.Sleep(delay)
+			}
+			worker(id, config, taskChan, resultChan, newTaskChan, retryChan, timeoutChan, stats, &workerWG, &scannedDirs, &dirMutex, words)
+		}(i, delay)
 	}
 
 	// Feed initial tasks to workers
 	go func() {
 		for _, target := range targets {
-			signatures := performCalibration(target, config)
+			signatures := getCalibrationSignatures(target, config)
+
+			isSPA := config.SPAAware && isSPASignature(signatures)
+			if isSPA {
+				spaTargets.Store(target, true)
+				fmt.Printf("%s[🧠 BRAIN-1]%s %s looks like a single-page app (every probe returns the same 200 page) - restricting to -spa-prefixes: %s\n",
+					ColorCyan+ColorBold, ColorReset, target, strings.Join(config.SPAPrefixes, ", "))
+			}
+
+			var robots *robotsRules
+			if config.RespectRobots {
+				robots = fetchRobotsRules(target, config)
+			}
 
-			for _, word := range words {
-				task := Task{TargetURL: target, Path: word, Depth: 1}
-				taskChan <- task
+			for i, word := range words {
+				path := "/" + strings.TrimPrefix(word, "/")
+				if isSPA && !hasSPAPrefix(path, config.SPAPrefixes) {
+					atomic.AddInt64(&stats.Total, -int64(1+len(config.Extensions)))
+					continue
+				}
+				if robots.blocks(path) {
+					atomic.AddInt64(&stats.Total, -1)
+				} else if knownURLs[buildURL(target, word)] {
+					atomic.AddInt64(&stats.Total, -1)
+				} else {
+					task := Task{TargetURL: target, Path: word, Depth: 1, WordIndex: i, Word: word, Source: "wordlist", IsBase: true}
+					taskChan <- task
+				}
 
 				for _, ext := range config.Extensions {
-					taskWithExt := Task{TargetURL: target, Path: word + ext, Depth: 1}
+					pathWithExt := "/" + strings.TrimPrefix(joinWordExt(word, ext), "/")
+					if robots.blocks(pathWithExt) {
+						atomic.AddInt64(&stats.Total, -1)
+						continue
+					}
+					if knownURLs[buildURL(target, joinWordExt(word, ext))] {
+						atomic.AddInt64(&stats.Total, -1)
+						continue
+					}
+					taskWithExt := Task{TargetURL: target, Path: joinWordExt(word, ext), Depth: 1, WordIndex: i, Word: word, Source: "wordlist"}
 					taskChan <- taskWithExt
 				}
 			}
@@ -377,6 +2033,26 @@ func main() {
 	// Wait for all tasks to complete
 	go func() {
 		workerWG.Wait()
+
+		// -retry-timeouts: give every task that timed out during the main
+		// scan one more shot, at a deliberately low concurrency so a
+		// slow/overloaded target isn't hit the same way that caused the
+		// timeouts to begin with
+		if timeoutChan != nil {
+			close(timeoutChan)
+			var timeoutWG sync.WaitGroup
+			concurrency := config.TimeoutRetryConcurrency
+			if concurrency < 1 {
+				concurrency = 1
+			}
+			for i := 0; i < concurrency; i++ {
+				timeoutWG.Add(1)
+				go worker(i, config, timeoutChan, resultChan, newTaskChan, retryChan, nil, stats, &timeoutWG, &scannedDirs, &dirMutex, words)
+			}
+			timeoutWG.Wait()
+		}
+
+		close(retryChan)
 		close(taskChan)
 		close(resultChan)
 		if config.MaxDepth > 0 {
@@ -390,729 +2066,6495 @@ func main() {
 
 	// Final output
 	elapsed := time.Since(stats.StartTime)
-	fmt.Printf("\n\n%s╔════════════════════════════════════════════════════════════════╗%s\n", ColorNeonGreen+ColorBold, ColorReset)
-	fmt.Printf("%s║                     💀 ATTACK COMPLETED 💀                     ║%s\n", ColorNeonGreen+ColorBold, ColorReset)
-	fmt.Printf("%s╚════════════════════════════════════════════════════════════════╝%s\n", ColorNeonGreen+ColorBold, ColorReset)
-	fmt.Printf("\n%s┌─ STATISTICS ─────────────────────────────────────────────────┐%s\n", ColorCyan, ColorReset)
-	fmt.Printf("%s│%s Total Requests:     %s%d%s\n", ColorCyan, ColorReset, ColorBold, stats.Processed, ColorReset)
-	fmt.Printf("%s│%s Findings:           %s%d%s\n", ColorCyan, ColorReset, ColorNeonGreen+ColorBold, stats.Found, ColorReset)
-	fmt.Printf("%s│%s Secrets Found:      %s%d%s\n", ColorCyan, ColorReset, ColorRed+ColorBold, stats.Secrets, ColorReset)
-	fmt.Printf("%s│%s WAF Detections:     %s%d%s\n", ColorCyan, ColorReset, ColorMagenta+ColorBold, stats.WAFHits, ColorReset)
-	fmt.Printf("%s│%s Errors:             %d\n", ColorCyan, ColorReset, stats.Errors)
-	fmt.Printf("%s│%s Duration:           %s\n", ColorCyan, ColorReset, elapsed.Round(time.Millisecond))
-	fmt.Printf("%s│%s Req/s:              %.2f\n", ColorCyan, ColorReset, float64(stats.Processed)/elapsed.Seconds())
-	fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+	finishScan(results, stats, config, elapsed, false)
+}
 
-	// Save results
-	if config.OutputFile != "" {
-		if err := saveResults(results, config.OutputFile); err != nil {
-			fmt.Printf("%s[ERROR]%s Failed to save results: %s\n", ColorRed+ColorBold, ColorReset, err)
-		} else {
-			fmt.Printf("%s[✓]%s Results saved to: %s\n", ColorNeonGreen, ColorReset, config.OutputFile)
-		}
+// finishScan prints the closing statistics box and writes every configured
+// report (JSON, per-status, HTML, canary log) for whatever results have been
+// collected so far. It is shared by the normal end-of-scan path and by
+// handleInterrupt, so an aborted scan still leaves a usable report on disk
+// instead of losing everything.
+// dedupeFindings collapses results that are byte-identical (same status
+// code and response body hash) into the first-seen result, recording the
+// others' URLs as AlternateURLs instead of letting /word, /word.php,
+// /word/ etc. inflate the report as separate findings. Results with no
+// ContentHash (produced by code paths that don't compute one) pass through
+// unchanged
+func dedupeFindings(results []Result) []Result {
+	type dedupKey struct {
+		status int
+		hash   string
 	}
+	seen := make(map[dedupKey]int)
+	deduped := make([]Result, 0, len(results))
 
-	if config.HTMLReport != "" {
-		if err := generateHTMLReport(results, config.HTMLReport, config); err != nil {
-			fmt.Printf("%s[ERROR]%s Failed to generate HTML report: %s\n", ColorRed+ColorBold, ColorReset, err)
-		} else {
-			fmt.Printf("%s[✓]%s HTML report saved to: %s\n", ColorNeonGreen, ColorReset, config.HTMLReport)
+	for _, r := range results {
+		if r.ContentHash == "" {
+			deduped = append(deduped, r)
+			continue
+		}
+		key := dedupKey{status: r.StatusCode, hash: r.ContentHash}
+		if idx, ok := seen[key]; ok {
+			deduped[idx].AlternateURLs = append(deduped[idx].AlternateURLs, r.URL)
+			continue
 		}
+		seen[key] = len(deduped)
+		deduped = append(deduped, r)
 	}
+
+	return deduped
 }
 
-func showBanner() {
-	banner := `
-   ██████╗ █████╗ ██████╗ ███████╗ █████╗ ██╗ ██████╗██╗███╗   ██╗
-  ██╔════╝██╔══██╗██╔══██╗██╔════╝██╔══██╗██║██╔════╝██║████╗  ██║
-  ██║     ███████║██████╔╝███████╗███████║██║██║     ██║██╔██╗ ██║
-  ██║     ██╔══██║██╔═══╝ ╚════██║██╔══██║██║██║     ██║██║╚██╗██║
-  ╚██████╗██║  ██║██║     ███████║██║  ██║██║╚██████╗██║██║ ╚████║
-   ╚═════╝╚═╝  ╚═╝╚═╝     ╚══════╝╚═╝  ╚═╝╚═╝ ╚═════╝╚═╝╚═╝  ╚═══╝
-`
-	subtitle := `
-  ▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄
-  █                      v1.5 RED TEAM EDITION                   █
-  █            Advanced Web Directory Fuzzer + WAF Hunter        █
-  █                     Developer: Hawtsauce                     █
-  █                Intelligence > Speed > Stealth                █
-  ▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀
-`
-	fmt.Printf("%s%s%s", ColorMagenta+ColorBold, banner, ColorReset)
-	fmt.Printf("%s%s%s\n", ColorNeonCyan, subtitle, ColorReset)
+// resolveNotifySink translates a -notify sink URL into the real HTTP
+// endpoint and payload shape it expects. slack:// and discord:// mirror
+// each service's incoming-webhook path convention (the part after
+// hooks.slack.com/services/ or discord.com/api/webhooks/); telegram://
+// carries the bot token and chat ID as token@chat-id, since Telegram's API
+// takes both as request parameters rather than baking them into a webhook path.
+func resolveNotifySink(sink string) (endpoint string, kind string, ok bool) {
+	switch {
+	case strings.HasPrefix(sink, "slack://"):
+		return "https://hooks.slack.com/services/" + strings.TrimPrefix(sink, "slack://"), "slack", true
+	case strings.HasPrefix(sink, "discord://"):
+		return "https://discord.com/api/webhooks/" + strings.TrimPrefix(sink, "discord://"), "discord", true
+	case strings.HasPrefix(sink, "telegram://"):
+		token, chatID, found := strings.Cut(strings.TrimPrefix(sink, "telegram://"), "@")
+		if !found {
+			return "", "", false
+		}
+		return fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", token, chatID), "telegram", true
+	default:
+		return "", "", false
+	}
 }
 
-func showAttackConfig(config Config, targets []string) {
-	fmt.Printf("\n%s╔══ ATTACK CONFIGURATION ══════════════════════════════════════╗%s\n", ColorOrange+ColorBold, ColorReset)
-	fmt.Printf("%s║%s\n", ColorOrange, ColorReset)
-
-	// Targets
-	if len(targets) == 1 {
-		fmt.Printf("%s║%s   🎯 Target:           %s%s%s\n", ColorOrange, ColorReset, ColorBold, targets[0], ColorReset)
-	} else {
-		fmt.Printf("%s║%s   🎯 Targets:         %s%d domains%s\n", ColorOrange, ColorReset, ColorBold, len(targets), ColorReset)
+// notifyHTTPClient is the shared, explicitly-timed client every -notify
+// sink posts through, so a slow/unresponsive webhook can't hang its
+// goroutine forever -- the same pattern used everywhere else a client is
+// built just for outbound requests (e.g. runServe, runController, runWorker)
+var notifyHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// notifyWaitGroup tracks in-flight -notify deliveries fired via "go
+// notifyCriticalFinding(...)", so the process can wait for them to land
+// before exiting instead of dropping whichever are still in flight
+var notifyWaitGroup sync.WaitGroup
+
+// sendNotification posts message to every configured -notify sink, shaped
+// to each service's expected JSON payload. A sink that fails to resolve or
+// errors on delivery is logged to stderr and skipped -- a broken webhook
+// shouldn't abort a scan.
+func sendNotification(config Config, message string) {
+	for _, sink := range config.NotifyURLs {
+		endpoint, kind, ok := resolveNotifySink(sink)
+		if !ok {
+			fmt.Printf("%s[WARN]%s Unrecognized -notify sink: %s\n", ColorYellow, ColorReset, sink)
+			continue
+		}
+		var payload []byte
+		switch kind {
+		case "discord":
+			payload, _ = json.Marshal(map[string]string{"content": message})
+		default: // slack, telegram
+			payload, _ = json.Marshal(map[string]string{"text": message})
+		}
+		resp, err := notifyHTTPClient.Post(endpoint, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			fmt.Printf("%s[WARN]%s Notification to %s sink failed: %s\n", ColorYellow, ColorReset, kind, err)
+			continue
+		}
+		resp.Body.Close()
 	}
+}
 
-	// Wordlist
-	fmt.Printf("%s║%s   📝 Wordlist:         %s\n", ColorOrange, ColorReset, config.Wordlist)
-
-	// Threads
-	fmt.Printf("%s║%s   ⚡ Threads:          %s%d%s\n", ColorOrange, ColorReset, ColorBold, config.Threads, ColorReset)
-
-	// Extensions
-	if len(config.Extensions) > 0 {
-		fmt.Printf("%s║%s   📦 Extensions:       %s\n", ColorOrange, ColorReset, strings.Join(config.Extensions, ", "))
+// notifyCriticalFinding sends a per-critical-finding -notify message,
+// including the curl command for one-click reproduction. Callers fire this
+// via "go notifyCriticalFinding(...)" and must have called
+// notifyWaitGroup.Add(1) first, since it always calls Done on return.
+func notifyCriticalFinding(config Config, result Result) {
+	defer notifyWaitGroup.Done()
+	if len(config.NotifyURLs) == 0 {
+		return
 	}
+	sendNotification(config, fmt.Sprintf("🚨 Critical finding: %d %s\n%s", result.StatusCode, result.URL, result.CurlCommand))
+}
 
-	// Recursive
-	if config.MaxDepth > 0 {
-		fmt.Printf("%s║%s   🔄 Recursive:        %sEnabled (Depth: %d)%s\n", ColorOrange, ColorReset, ColorNeonGreen, config.MaxDepth, ColorReset)
+// notifyScanComplete sends a summary-on-complete -notify message once the
+// scan finishes
+func notifyScanComplete(config Config, results []Result, elapsed time.Duration) {
+	if len(config.NotifyURLs) == 0 {
+		return
 	}
-
-	// Custom Headers
-	if len(config.CustomHeaders) > 0 {
-		fmt.Printf("%s║%s   🔑 Custom Headers:   %s%d configured%s\n", ColorOrange, ColorReset, ColorBold, len(config.CustomHeaders), ColorReset)
-		for key := range config.CustomHeaders {
-			fmt.Printf("%s║%s      └─ %s\n", ColorOrange, ColorReset, key)
+	critical := 0
+	for _, r := range results {
+		if r.Critical {
+			critical++
 		}
 	}
-
-	// Modules
-	fmt.Printf("%s║%s\n", ColorOrange, ColorReset)
-	fmt.Printf("%s║%s   %s🛡️  WAF Detection:%s      ACTIVE\n", ColorOrange, ColorReset, ColorMagenta, ColorReset)
-	fmt.Printf("%s║%s   %s🔐 Secret Scanner:%s      ACTIVE (%d patterns)\n", ColorOrange, ColorReset, ColorRed, ColorReset, len(secretPatterns))
-	fmt.Printf("%s║%s   %s🔥 Method Fuzzing:%s     ACTIVE\n", ColorOrange, ColorReset, ColorYellow, ColorReset)
-	fmt.Printf("%s║%s   %s🧠 Smart Calibration:%s  ACTIVE\n", ColorOrange, ColorReset, ColorCyan, ColorReset)
-
-	fmt.Printf("%s║%s\n", ColorOrange, ColorReset)
-	fmt.Printf("%s╚══════════════════════════════════════════════════════════════╝%s\n", ColorOrange+ColorBold, ColorReset)
+	sendNotification(config, fmt.Sprintf("✅ Scan of %s complete in %s -- %d finding(s), %d critical",
+		config.TargetURL, elapsed.Round(time.Second), len(results), critical))
 }
 
-func parseFlags() Config {
-	config := Config{
-		CustomHeaders: make(map[string]string),
-	}
-
-	var headers headerFlags
-
-	flag.StringVar(&config.TargetURL, "u", "", "Target URL (or use STDIN for multiple targets)")
-	flag.StringVar(&config.Wordlist, "w", "", "Wordlist path (required)")
-	flag.IntVar(&config.Threads, "t", 50, "Number of concurrent threads")
-	extensions := flag.String("x", "", "Extensions (comma-separated, e.g., php,html,txt)")
-	flag.IntVar(&config.Timeout, "timeout", 10, "Request timeout in seconds")
-	flag.StringVar(&config.OutputFile, "o", "", "Output file (JSON format)")
-	flag.StringVar(&config.HTMLReport, "html", "", "Generate HTML report")
-	flag.BoolVar(&config.Verbose, "v", false, "Verbose mode (print every request)")
-	flag.IntVar(&config.MaxDepth, "depth", 0, "Recursive scanning depth (0=disabled)")
-	flag.Var(&headers, "H", "Custom header (can be used multiple times)")
-
-	// Custom usage
-	flag.Usage = func() {
-		fmt.Printf("%s", ColorNeonCyan+ColorBold)
-		fmt.Printf(`
-  ╔═══════════════════════════════════════════════════════════════╗
-  ║           CAPSAICIN v1.5 RED TEAM - Usage Guide               ║
-  ╚═══════════════════════════════════════════════════════════════╝
-`)
-		fmt.Printf("%s\n", ColorReset)
-		fmt.Printf("%sREQUIRED FLAGS:%s\n", ColorOrange+ColorBold, ColorReset)
-		fmt.Printf("  -u string       Target URL (or pipe via STDIN)\n")
-		fmt.Printf("  -w string       Path to wordlist file\n\n")
+func finishScan(results []Result, stats *Stats, config Config, elapsed time.Duration, interrupted bool) {
+	results = dedupeFindings(results)
 
-		fmt.Printf("%sOPTIONAL FLAGS:%s\n", ColorOrange+ColorBold, ColorReset)
-		fmt.Printf("  -t int         Concurrent threads (default: 50)\n")
-		fmt.Printf("  -x string       Extensions (comma-separated)\n")
-		fmt.Printf("  -H string       Custom headers (repeatable)\n")
-		fmt.Printf("  --timeout int  Request timeout in seconds (default: 10)\n")
-		fmt.Printf("  --depth int    Recursive scanning depth (0=disabled)\n")
-		fmt.Printf("  -v             Verbose mode\n")
-		fmt.Printf("  -o string       JSON output file\n")
-		fmt.Printf("  --html string  HTML report file\n\n")
+	// Wait for every per-critical-finding notification fired during the
+	// scan to land before sending the summary (and before the process can
+	// exit) -- otherwise a fast scan can finish and exit while those
+	// goroutines are still in flight, silently dropping exactly the
+	// alerts -notify exists to deliver
+	notifyWaitGroup.Wait()
+	notifyScanComplete(config, results, elapsed)
 
-		fmt.Printf("%sEXAMPLES:%s\n", ColorNeonGreen+ColorBold, ColorReset)
-		fmt.Printf("  # Basic scan\n")
-		fmt.Printf("  capsaicin -u https://target.com -w wordlist.txt\n\n")
-		fmt.Printf("  # With authentication\n")
-		fmt.Printf("  capsaicin -u https://api.target.com -w words.txt \\\n")
-		fmt.Printf("    -H \"Authorization: Bearer token123\" \\\n")
-		fmt.Printf("    -H \"Cookie: session=abc\"\n\n")
-		fmt.Printf("  # Multi-target scan\n")
-		fmt.Printf("  cat targets.txt | capsaicin -w wordlist.txt -t 100\n\n")
+	var baseline []Result
+	if config.BaselineFile != "" {
+		loaded, err := loadBaseline(config.BaselineFile)
+		if err != nil {
+			fmt.Printf("%s[WARN]%s Failed to load baseline: %s\n", ColorYellow, ColorReset, err)
+		} else {
+			baseline = loaded
+			results = applyFindingAging(results, baseline)
+		}
 	}
 
-	flag.Parse()
-
-	// Parse extensions
-	if *extensions != "" {
-		config.Extensions = strings.Split(*extensions, ",")
-		for i := range config.Extensions {
-			config.Extensions[i] = strings.TrimSpace(config.Extensions[i])
-			if !strings.HasPrefix(config.Extensions[i], ".") {
-				config.Extensions[i] = "." + config.Extensions[i]
-			}
+	if !config.Stream {
+		if interrupted {
+			fmt.Printf("\n\n%s╔════════════════════════════════════════════════════════════════╗%s\n", ColorYellow+ColorBold, ColorReset)
+			fmt.Printf("%s║                  🛑 SCAN INTERRUPTED — SAVING 🛑                  ║%s\n", ColorYellow+ColorBold, ColorReset)
+			fmt.Printf("%s╚════════════════════════════════════════════════════════════════╝%s\n", ColorYellow+ColorBold, ColorReset)
+		} else {
+			fmt.Printf("\n\n%s╔════════════════════════════════════════════════════════════════╗%s\n", ColorNeonGreen+ColorBold, ColorReset)
+			fmt.Printf("%s║                     💀 ATTACK COMPLETED 💀                     ║%s\n", ColorNeonGreen+ColorBold, ColorReset)
+			fmt.Printf("%s╚════════════════════════════════════════════════════════════════╝%s\n", ColorNeonGreen+ColorBold, ColorReset)
+		}
+		fmt.Printf("\n%s┌─ STATISTICS ─────────────────────────────────────────────────┐%s\n", ColorCyan, ColorReset)
+		fmt.Printf("%s│%s Total Requests:     %s%d%s\n", ColorCyan, ColorReset, ColorBold, stats.Processed, ColorReset)
+		fmt.Printf("%s│%s Findings:           %s%d%s\n", ColorCyan, ColorReset, ColorNeonGreen+ColorBold, stats.Found, ColorReset)
+		fmt.Printf("%s│%s Secrets Found:      %s%d%s\n", ColorCyan, ColorReset, ColorRed+ColorBold, stats.Secrets, ColorReset)
+		fmt.Printf("%s│%s PII Found:          %s%d%s\n", ColorCyan, ColorReset, ColorOrange+ColorBold, stats.PII, ColorReset)
+		fmt.Printf("%s│%s Info Disclosure:    %s%d%s\n", ColorCyan, ColorReset, ColorYellow+ColorBold, stats.InfoDisclosure, ColorReset)
+		fmt.Printf("%s│%s WAF Detections:     %s%d%s\n", ColorCyan, ColorReset, ColorMagenta+ColorBold, stats.WAFHits, ColorReset)
+		fmt.Printf("%s│%s Throttled (429/503):%d\n", ColorCyan, ColorReset, stats.Throttled)
+		if stats.DestructiveSkipped > 0 {
+			fmt.Printf("%s│%s Destructive Skipped:%d\n", ColorCyan, ColorReset, stats.DestructiveSkipped)
 		}
+		fmt.Printf("%s│%s Errors:             %d\n", ColorCyan, ColorReset, stats.Errors)
+		fmt.Printf("%s│%s Duration:           %s\n", ColorCyan, ColorReset, elapsed.Round(time.Millisecond))
+		fmt.Printf("%s│%s Req/s:              %.2f\n", ColorCyan, ColorReset, float64(stats.Processed)/elapsed.Seconds())
+		fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+		printCookieAudit(results)
+		printCSPAudit(results)
+		printMethodHeaderDiffReport(results)
+		printWordlistEfficacyReport(buildWordlistEfficacyReport(results))
+		printProxyHealthReport(proxyPool)
 	}
 
-	// Parse custom headers
-	for _, h := range headers {
-		parts := strings.SplitN(h, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			config.CustomHeaders[key] = value
+	if config.WordlistReportFile != "" {
+		if err := saveWordlistEfficacyReport(buildWordlistEfficacyReport(results), config.WordlistReportFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save wordlist efficacy report: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Wordlist efficacy report saved to: %s\n", ColorNeonGreen, ColorReset, config.WordlistReportFile)
 		}
 	}
 
-	return config
-}
-
-func validateConfig(config *Config, targets []string) error {
-	if len(targets) == 0 {
-		return fmt.Errorf("no targets specified")
+	// Save results
+	if config.OutputFile != "" {
+		if err := saveResults(results, config.OutputFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save results: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Results saved to: %s\n", ColorNeonGreen, ColorReset, config.OutputFile)
+		}
 	}
 
-	for i := range targets {
-		if !strings.HasPrefix(targets[i], "http://") && !strings.HasPrefix(targets[i], "https://") {
-			targets[i] = "http://" + targets[i]
+	if config.OutputByStatusDir != "" {
+		if err := saveResultsByStatus(results, config.OutputByStatusDir); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save per-status results: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Per-status results saved to: %s\n", ColorNeonGreen, ColorReset, config.OutputByStatusDir)
 		}
 	}
 
-	if config.Wordlist == "" {
-		return fmt.Errorf("wordlist is required (-w)")
-	}
-	if _, err := os.Stat(config.Wordlist); os.IsNotExist(err) {
-		return fmt.Errorf("wordlist file not found: %s", config.Wordlist)
+	if config.HTMLReport != "" {
+		if err := generateHTMLReport(results, config.HTMLReport, config, baseline); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to generate HTML report: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s HTML report saved to: %s\n", ColorNeonGreen, ColorReset, config.HTMLReport)
+		}
 	}
-	return nil
-}
 
-// BRAIN 1: Smart Auto-Calibration
-func performCalibration(targetURL string, config Config) []ResponseSignature {
-	signatures := make([]ResponseSignature, 0, 3)
-	randomPaths := []string{
-		fmt.Sprintf("/capsaicin_calibration_%d", rand.Intn(999999)),
-		fmt.Sprintf("/random_nonexistent_%d", rand.Intn(999999)),
-		fmt.Sprintf("/test_404_path_%d", rand.Intn(999999)),
+	if config.SARIFReportFile != "" {
+		if err := saveSARIFReport(results, config.SARIFReportFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to generate SARIF report: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s SARIF report saved to: %s\n", ColorNeonGreen, ColorReset, config.SARIFReportFile)
+		}
 	}
 
-	fmt.Printf("%s[🧠 BRAIN-1]%s Calibrating: %s\n", ColorCyan+ColorBold, ColorReset, targetURL)
-
-	for _, path := range randomPaths {
-		url := strings.TrimSuffix(targetURL, "/") + path
-		sig := fetchSignature(url, config)
-		if sig != nil {
-			signatures = append(signatures, *sig)
+	if config.CanaryLogFile != "" {
+		if err := saveCanaryLog(config.CanaryLogFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save canary log: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Canary registry saved to: %s\n", ColorNeonGreen, ColorReset, config.CanaryLogFile)
 		}
 	}
 
-	return signatures
-}
-
-func fetchSignature(url string, config Config) *ResponseSignature {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil
+	if config.SummaryFile != "" {
+		summary := buildScanSummary(results, stats, elapsed, interrupted)
+		if err := saveScanSummary(summary, config.SummaryFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save scan summary: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Scan summary saved to: %s\n", ColorNeonGreen, ColorReset, config.SummaryFile)
+		}
 	}
-	req.Header.Set("User-Agent", getRandomUserAgent())
 
-	// Apply custom headers
-	for key, value := range config.CustomHeaders {
-		req.Header.Set(key, value)
+	if config.EvidenceDir != "" {
+		manifestPath := strings.TrimSuffix(config.EvidenceDir, "/") + "/manifest.json"
+		if err := saveEvidenceManifest(manifestPath); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save evidence manifest: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Evidence manifest saved to: %s\n", ColorNeonGreen, ColorReset, manifestPath)
+		}
 	}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil
+	if config.HarvestFile != "" {
+		if err := saveHarvest(config.HarvestFile); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save harvest file: %s\n", ColorRed+ColorBold, ColorReset, err)
+		} else {
+			fmt.Printf("%s[✓]%s Harvested contacts saved to: %s\n", ColorNeonGreen, ColorReset, config.HarvestFile)
+		}
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil
+	if len(config.FailOn) > 0 {
+		if matched := matchedFailOnCategories(results, config.FailOn); len(matched) > 0 {
+			fmt.Printf("%s[✗]%s -fail-on matched: %s — exiting non-zero for CI\n", ColorRed+ColorBold, ColorReset, strings.Join(matched, ", "))
+			os.Exit(2)
+		}
 	}
 
-	return &ResponseSignature{
-		StatusCode: resp.StatusCode,
-		Size:       len(body),
-		WordCount:  len(strings.Fields(string(body))),
-		LineCount:  strings.Count(string(body), "\n") + 1,
+	if config.CompareBaselineFile != "" {
+		baseline, err := loadBaselineFile(config.CompareBaselineFile, config.BaselineKeyFile)
+		if err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to load -compare-baseline: %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+		if drift := diffResultsAgainstBaseline(results, baseline); len(drift) > 0 {
+			fmt.Printf("%s[✗]%s %d path(s) drifted from the approved baseline:\n", ColorRed+ColorBold, ColorReset, len(drift))
+			for _, d := range drift {
+				fmt.Printf("    %s: %s\n", d.Path, d.Reason)
+			}
+			os.Exit(2)
+		}
+		fmt.Printf("%s[✓]%s No drift from approved baseline %s\n", ColorNeonGreen, ColorReset, config.CompareBaselineFile)
 	}
 }
 
-func loadWordlist(path string) ([]string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// matchesFailOnCategory reports whether result falls under one -fail-on
+// category: a finding class (secrets, critical, pii, waf), a status class
+// ("2xx".."5xx"), or an exact status code ("200")
+func matchesFailOnCategory(result Result, category string) bool {
+	switch category {
+	case "secrets":
+		return result.SecretFound
+	case "critical":
+		return result.Critical
+	case "pii":
+		return result.PIIFound
+	case "waf":
+		return result.WAFDetected != ""
 	}
-	defer file.Close()
+	if len(category) == 3 && strings.HasSuffix(category, "xx") {
+		class := string(category[0])
+		return strconv.Itoa(result.StatusCode/100) == class
+	}
+	if code, err := strconv.Atoi(category); err == nil {
+		return result.StatusCode == code
+	}
+	return false
+}
 
-	var words []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		word := strings.TrimSpace(scanner.Text())
-		if word != "" && !strings.HasPrefix(word, "#") {
-			words = append(words, word)
+// matchedFailOnCategories returns the distinct -fail-on categories that
+// matched at least one result, for a CI-gating exit decision
+func matchedFailOnCategories(results []Result, categories []string) []string {
+	var matched []string
+	for _, category := range categories {
+		for _, r := range results {
+			if matchesFailOnCategory(r, category) {
+				matched = append(matched, category)
+				break
+			}
 		}
 	}
-
-	return words, scanner.Err()
+	return matched
 }
 
-// Worker function with all advanced features
-func worker(id int, config Config, tasks <-chan Task, results chan<- Result, newTasks chan<- Task,
-	stats *Stats, wg *sync.WaitGroup, scannedDirs *map[string]map[string]bool, dirMutex *sync.Mutex,
-	targets []string, words []string) {
-	defer wg.Done()
-
-	consecutiveErrors := 0
-	maxConsecutiveErrors := 5
-
-	targetSignatures := make(map[string][]ResponseSignature)
-	for _, target := range targets {
-		targetSignatures[target] = performCalibration(target, config)
+// scanPaused and activeThreadCap are the shared state -keyboard-controls'
+// hotkeys mutate and worker() polls. activeThreadCap is only meaningful
+// while KeyboardControls is set -- it starts at 0 otherwise, and worker()
+// only consults it when the flag is on, so it never throttles a normal run
+var scanPaused atomic.Bool
+var activeThreadCap atomic.Int64
+
+// watchKeyboardControls implements -keyboard-controls: runtime hotkeys
+// read one byte at a time from a raw-mode stdin, like ffuf's interactive
+// mode. It silently gives up if raw mode can't be entered (e.g. stdin
+// isn't a terminal, or the platform has no enableRawMode implementation).
+func watchKeyboardControls(stats *Stats, taskChan chan Task, resultChan chan Result, results *[]Result, resultsMutex *sync.Mutex, config Config, targets []string) {
+	if !enableRawMode() {
+		return
 	}
+	defer disableRawMode()
 
-	for task := range tasks {
-		url := strings.TrimSuffix(task.TargetURL, "/") + "/" + strings.TrimPrefix(task.Path, "/")
-
-		if config.Verbose {
-			fmt.Printf("%s[→]%s Testing: %s\n", ColorCyan, ColorReset, url)
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil || n == 0 {
+			return
 		}
-
-		userAgent := getRandomUserAgent()
-		result, bodyContent, err := makeRequestWithUA(url, "GET", userAgent, config)
-		atomic.AddInt64(&stats.Processed, 1)
-
-		if err != nil {
-			atomic.AddInt64(&stats.Errors, 1)
-			consecutiveErrors++
-
-			if consecutiveErrors >= maxConsecutiveErrors {
-				time.Sleep(2 * time.Second)
-				consecutiveErrors = 0
+		switch buf[0] {
+		case 'p', 'P':
+			paused := !scanPaused.Load()
+			scanPaused.Store(paused)
+			if paused {
+				fmt.Fprintf(os.Stderr, "\n%s[⏸ PAUSED]%s press p to resume\n", ColorYellow+ColorBold, ColorReset)
+			} else {
+				fmt.Fprintf(os.Stderr, "\n%s[▶ RESUMED]%s\n", ColorNeonGreen+ColorBold, ColorReset)
 			}
-			continue
+		case '+':
+			newCap := activeThreadCap.Add(1)
+			if newCap > int64(config.Threads) {
+				newCap = int64(config.Threads)
+				activeThreadCap.Store(newCap)
+			}
+			fmt.Fprintf(os.Stderr, "\n%s[+]%s Active threads: %d/%d\n", ColorCyan, ColorReset, newCap, config.Threads)
+		case '-':
+			newCap := activeThreadCap.Add(-1)
+			if newCap < 1 {
+				newCap = 1
+				activeThreadCap.Store(newCap)
+			}
+			fmt.Fprintf(os.Stderr, "\n%s[-]%s Active threads: %d/%d\n", ColorCyan, ColorReset, newCap, config.Threads)
+		case 's', 'S':
+			printStatsSnapshot(stats, taskChan, resultChan, targets, os.Stderr)
+		case 'q', 'Q':
+			fmt.Fprintf(os.Stderr, "\n%s[q]%s Finishing gracefully -- writing results collected so far\n", ColorYellow+ColorBold, ColorReset)
+			resultsMutex.Lock()
+			snapshot := make([]Result, len(*results))
+			copy(snapshot, *results)
+			resultsMutex.Unlock()
+			elapsed := time.Since(stats.StartTime)
+			finishScan(snapshot, stats, config, elapsed, true)
+			os.Exit(0)
 		}
+	}
+}
 
-		consecutiveErrors = 0
+// watchInterrupt traps SIGINT/SIGTERM so an aborted scan still produces a
+// report instead of silently discarding every result collected so far. It
+// snapshots whatever has been appended to results under resultsMutex, then
+// calls finishScan directly and exits — mirroring the normal end-of-main
+// report-writing path rather than duplicating it.
+func watchInterrupt(results *[]Result, resultsMutex *sync.Mutex, stats *Stats, config Config) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	resultsMutex.Lock()
+	snapshot := make([]Result, len(*results))
+	copy(snapshot, *results)
+	resultsMutex.Unlock()
 
-		if matchesSignature(result, targetSignatures[task.TargetURL]) {
-			continue
-		}
+	elapsed := time.Since(stats.StartTime)
+	finishScan(snapshot, stats, config, elapsed, true)
+	os.Exit(130)
+}
 
-		// HTTP Method Fuzzing on 405
-		if result.StatusCode == 405 {
-			alternativeMethods := []string{"POST", "PUT", "DELETE", "PATCH"}
-			for _, method := range alternativeMethods {
-				methodResult, methodBody, err := makeRequestWithUA(url, method, userAgent, config)
-				if err == nil && (methodResult.StatusCode == 200 || methodResult.StatusCode == 201 || methodResult.StatusCode == 204) {
-					methodResult.Method = method
-					methodResult.Critical = true
+// runAnalyze implements the downstream side of the NDJSON pipe protocol:
+// "capsaicin scan ... -stream | capsaicin analyze -" reads findings emitted
+// by an upstream capsaicin instance and applies additional modules (here, a
+// deep secret-scanning refetch) before re-emitting them as NDJSON
+func runAnalyze(args []string) {
+	var input io.Reader = os.Stdin
+	if len(args) > 0 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] Failed to open %s: %s\n", args[0], err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		input = file
+	}
 
-					if secrets := detectSecrets(methodBody); len(secrets) > 0 {
-						methodResult.SecretFound = true
-						methodResult.SecretTypes = secrets
-						atomic.AddInt64(&stats.Secrets, 1)
-					}
+	httpClient = &http.Client{Timeout: 10 * time.Second}
 
-					atomic.AddInt64(&stats.Found, 1)
-					results <- *methodResult
-					break
-				}
+	decoder := json.NewDecoder(input)
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "[ERROR] Failed to decode finding: %s\n", err)
 			}
+			break
 		}
 
-		if isInteresting(result) {
-			atomic.AddInt64(&stats.Found, 1)
-
-			// Secret Detection
-			if result.StatusCode == 200 && len(bodyContent) > 0 {
-				if secrets := detectSecrets(bodyContent); len(secrets) > 0 {
+		if !result.SecretFound && result.StatusCode == 200 {
+			if resp, err := httpClient.Get(result.URL); err == nil {
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if secrets := detectSecrets(string(body)); len(secrets) > 0 {
 					result.SecretFound = true
 					result.SecretTypes = secrets
-					atomic.AddInt64(&stats.Secrets, 1)
+					result.SecretClassification = classifySecretMaterial(string(body), secrets)
 				}
 			}
+		}
+
+		encoder.Encode(result)
+	}
+}
+
+// serveJob tracks one scan launched via "capsaicin serve"'s REST API.
+// Rather than refactor main()'s scan loop into a library call, serve mode
+// spawns this same binary as a subprocess with -stream and tails its
+// NDJSON stdout -- the same pipe protocol "capsaicin scan ... -stream |
+// capsaicin analyze -" already uses (see runAnalyze). That makes
+// cancellation a regular process kill and status a regular process wait,
+// instead of requiring new internal scan-engine plumbing.
+type serveJob struct {
+	ID        string    `json:"id"`
+	Target    string    `json:"target"`
+	Status    string    `json:"status"` // "running", "done", "canceled", "error"
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitempty"`
+
+	mutex       sync.Mutex
+	cmd         *exec.Cmd
+	results     []Result
+	subscribers map[chan Result]bool
+}
+
+func (j *serveJob) appendResult(r Result) {
+	j.mutex.Lock()
+	j.results = append(j.results, r)
+	for sub := range j.subscribers {
+		select {
+		case sub <- r:
+		default:
+			// slow subscriber; drop rather than block the tailing goroutine
+		}
+	}
+	j.mutex.Unlock()
+}
+
+func (j *serveJob) subscribe() chan Result {
+	ch := make(chan Result, 64)
+	j.mutex.Lock()
+	if j.subscribers == nil {
+		j.subscribers = make(map[chan Result]bool)
+	}
+	j.subscribers[ch] = true
+	j.mutex.Unlock()
+	return ch
+}
+
+func (j *serveJob) unsubscribe(ch chan Result) {
+	j.mutex.Lock()
+	delete(j.subscribers, ch)
+	j.mutex.Unlock()
+	close(ch)
+}
+
+func (j *serveJob) snapshot() serveJob {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return serveJob{ID: j.ID, Target: j.Target, Status: j.Status, Error: j.Error, StartedAt: j.StartedAt, EndedAt: j.EndedAt}
+}
+
+var serveJobCounter int64
+var serveJobs sync.Map // map[string]*serveJob
+
+// runServe implements "capsaicin serve": a long-lived REST API for
+// starting scans, polling their status, streaming findings as they're
+// found (Server-Sent Events), and canceling a running scan. There's no
+// WebSocket support -- hand-rolling RFC 6455 framing is out of scope for
+// the zero-dependency build, and SSE covers the same one-way
+// findings-stream use case over plain stdlib net/http.
+// requireServeToken wraps a handler so every request must carry
+// "Authorization: Bearer <token>" -- capsaicin serve has no other access
+// control, and without this an unauthenticated network caller could drive
+// /scans to read arbitrary local files back through -w and SSRF the
+// scanner at any target with none of the scope/safety flags applied
+func requireServeToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8090", "Address to listen on -- loopback by default, since the only access control is -token")
+	token := fs.String("token", "", "Bearer token required on every request (Authorization: Bearer <token>); generated and printed once if not set")
+	allowIPs := fs.String("allow-ips", "", "Comma-separated CIDR ranges every spawned scan is restricted to, regardless of the target a caller submits (fails closed)")
+	denyIPs := fs.String("deny-ips", "", "Comma-separated CIDR ranges every spawned scan refuses to connect to, regardless of the target a caller submits (fails closed)")
+	respectRobots := fs.Bool("respect-robots", false, "Every spawned scan fetches and honors robots.txt, for polite self-assessment deployments")
+	fs.Parse(args)
+
+	if *token == "" {
+		tokenBytes := make([]byte, 24)
+		crand.Read(tokenBytes)
+		*token = hex.EncodeToString(tokenBytes)
+		fmt.Printf("%s[WARN]%s No -token set -- generated one. Every request must carry it: Authorization: Bearer %s\n",
+			ColorYellow, ColorReset, *token)
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to resolve own executable path: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/scans", requireServeToken(*token, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Target   string `json:"target"`
+			Wordlist string `json:"wordlist"`
+			Threads  int    `json:"threads"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+		if req.Target == "" || req.Wordlist == "" {
+			http.Error(w, "target and wordlist are required", http.StatusBadRequest)
+			return
+		}
+		if req.Threads <= 0 {
+			req.Threads = 50
+		}
+
+		id := fmt.Sprintf("scan-%d", atomic.AddInt64(&serveJobCounter, 1))
+		job := &serveJob{ID: id, Target: req.Target, Status: "running", StartedAt: time.Now()}
+		serveJobs.Store(id, job)
+
+		// allow-ips/deny-ips/respect-robots come from this process's own
+		// startup flags, not the request body -- a caller that can already
+		// authenticate still shouldn't be able to waive scope restrictions
+		// the server operator configured
+		cmdArgs := []string{"-u", req.Target, "-w", req.Wordlist, "-t", strconv.Itoa(req.Threads), "-stream"}
+		if *allowIPs != "" {
+			cmdArgs = append(cmdArgs, "-allow-ips", *allowIPs)
+		}
+		if *denyIPs != "" {
+			cmdArgs = append(cmdArgs, "-deny-ips", *denyIPs)
+		}
+		if *respectRobots {
+			cmdArgs = append(cmdArgs, "-respect-robots")
+		}
+		cmd := exec.Command(exePath, cmdArgs...)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to start scan: %s", err), http.StatusInternalServerError)
+			return
+		}
+		job.cmd = cmd
+		if err := cmd.Start(); err != nil {
+			http.Error(w, fmt.Sprintf("failed to start scan: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		go func() {
+			decoder := json.NewDecoder(stdout)
+			for {
+				var result Result
+				if err := decoder.Decode(&result); err != nil {
+					break
+				}
+				job.appendResult(result)
+			}
+			err := cmd.Wait()
+			job.mutex.Lock()
+			job.EndedAt = time.Now()
+			switch {
+			case job.Status == "canceled":
+				// already marked by the DELETE handler
+			case err != nil:
+				job.Status = "error"
+				job.Error = err.Error()
+			default:
+				job.Status = "done"
+			}
+			job.mutex.Unlock()
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job.snapshot())
+	}))
+
+	mux.HandleFunc("/scans/", requireServeToken(*token, func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/scans/")
+		id, sub, hasSub := strings.Cut(id, "/")
+
+		jobAny, ok := serveJobs.Load(id)
+		if !ok {
+			http.Error(w, "unknown scan id", http.StatusNotFound)
+			return
+		}
+		job := jobAny.(*serveJob)
+
+		switch {
+		case r.Method == http.MethodDelete && !hasSub:
+			job.mutex.Lock()
+			if job.Status == "running" && job.cmd != nil && job.cmd.Process != nil {
+				job.cmd.Process.Kill()
+				job.Status = "canceled"
+				job.EndedAt = time.Now()
+			}
+			job.mutex.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(job.snapshot())
+
+		case r.Method == http.MethodGet && hasSub && sub == "events":
+			flusher, ok := w.(http.Flusher)
+			if !ok {
+				http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+				return
+			}
+			ch := job.subscribe()
+			defer job.unsubscribe(ch)
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			for {
+				select {
+				case result, open := <-ch:
+					if !open {
+						return
+					}
+					data, _ := json.Marshal(result)
+					fmt.Fprintf(w, "data: %s\n\n", data)
+					flusher.Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+		case r.Method == http.MethodGet && !hasSub:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(job.snapshot())
+
+		default:
+			http.Error(w, "unsupported method/path", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	fmt.Printf("%s[✓]%s capsaicin serve listening on %s, Bearer token required (POST /scans, GET /scans/{id}, GET /scans/{id}/events, DELETE /scans/{id})\n",
+		ColorNeonGreen, ColorReset, *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Printf("%s[ERROR]%s Server stopped: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+}
+
+// distTask is one unit of work handed out by "capsaicin controller" and
+// claimed by "capsaicin worker" via GET /task
+type distTask struct {
+	Target string `json:"target"`
+	Word   string `json:"word"`
+}
+
+// distController holds the shared task queue and accumulated results for
+// "capsaicin controller". There's no Redis/NATS client available under
+// the zero-dependency build, so this is a minimal stdlib-only substitute
+// for the same producer/consumer contract: GET /task pops the next word,
+// POST /result pushes a finding back. Swapping in a real broker later
+// would only touch these two handlers.
+type distController struct {
+	target  string
+	words   []string
+	nextIdx int64
+
+	mutex   sync.Mutex
+	results []Result
+}
+
+func (c *distController) nextTask() (distTask, bool) {
+	idx := atomic.AddInt64(&c.nextIdx, 1) - 1
+	if idx >= int64(len(c.words)) {
+		return distTask{}, false
+	}
+	return distTask{Target: c.target, Word: c.words[idx]}, true
+}
+
+func (c *distController) addResult(r Result) {
+	c.mutex.Lock()
+	c.results = append(c.results, r)
+	c.mutex.Unlock()
+}
+
+func (c *distController) snapshot() []Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	out := make([]Result, len(c.results))
+	copy(out, c.results)
+	return out
+}
+
+// runController implements "capsaicin controller": shards a single
+// target's wordlist across however many "capsaicin worker" processes
+// connect, over a minimal HTTP task queue, and saves whatever results have
+// been reported back when interrupted
+func runController(args []string) {
+	fs := flag.NewFlagSet("controller", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:9091", "Address to listen on -- loopback by default, since the only access control is -token")
+	targetURL := fs.String("u", "", "Target URL to shard across workers")
+	wordlist := fs.String("w", "", "Wordlist to shard")
+	output := fs.String("o", "controller-results.json", "Output file for accumulated results")
+	token := fs.String("token", "", "Bearer token workers must present (Authorization: Bearer <token>); generated and printed once if not set")
+	fs.Parse(args)
+
+	if *targetURL == "" || *wordlist == "" {
+		fmt.Printf("%s[ERROR]%s controller requires -u and -w\n", ColorRed+ColorBold, ColorReset)
+		os.Exit(1)
+	}
+	words, err := loadWordlist(*wordlist)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to load wordlist: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+
+	if *token == "" {
+		tokenBytes := make([]byte, 24)
+		crand.Read(tokenBytes)
+		*token = hex.EncodeToString(tokenBytes)
+		fmt.Printf("%s[WARN]%s No -token set -- generated one. Every worker must pass -token %s\n", ColorYellow, ColorReset, *token)
+	}
+
+	controller := &distController{target: *targetURL, words: words}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/task", requireServeToken(*token, func(w http.ResponseWriter, r *http.Request) {
+		task, ok := controller.nextTask()
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(task)
+	}))
+	mux.HandleFunc("/result", requireServeToken(*token, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		var result Result
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			http.Error(w, fmt.Sprintf("invalid result body: %s", err), http.StatusBadRequest)
+			return
+		}
+		controller.addResult(result)
+		fmt.Printf("%s[+]%s %d %s (reported by worker)\n", ColorNeonGreen, ColorReset, result.StatusCode, result.URL)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	mux.HandleFunc("/status", requireServeToken(*token, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			TotalWords int `json:"total_words"`
+			Remaining  int `json:"remaining"`
+			Results    int `json:"results"`
+		}{
+			TotalWords: len(words),
+			Remaining:  max(0, len(words)-int(atomic.LoadInt64(&controller.nextIdx))),
+			Results:    len(controller.snapshot()),
+		})
+	}))
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		<-sigChan
+		fmt.Printf("\n%s[✓]%s Saving %d accumulated result(s) to %s\n", ColorNeonGreen, ColorReset, len(controller.snapshot()), *output)
+		if err := saveResults(controller.snapshot(), *output); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to save results: %s\n", ColorRed+ColorBold, ColorReset, err)
+		}
+		os.Exit(0)
+	}()
+
+	fmt.Printf("%s[✓]%s capsaicin controller sharding %d word(s) against %s on %s (GET /task, POST /result, GET /status)\n",
+		ColorNeonGreen, ColorReset, len(words), *targetURL, *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Printf("%s[ERROR]%s Controller stopped: %s\n", ColorRed+ColorBold, ColorReset, err)
+		os.Exit(1)
+	}
+}
+
+// runWorker implements "capsaicin worker": polls a "capsaicin controller"
+// for tasks and reports findings back, for distributed scanning across
+// very large scopes. It runs a lightweight GET + status-code + secret-scan
+// check per task rather than the full local scanning pipeline (WAF
+// detection, bypass/mutation modules, recursion, ...) -- those lean on a
+// lot of local state (calibration signatures, per-target streak counters)
+// that doesn't fit a simple wire task, so distributed mode trades depth
+// for horizontal scale.
+func runWorker(args []string) {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	controllerAddr := fs.String("controller", "", "Controller URL, e.g. http://host:9091")
+	threads := fs.Int("t", 20, "Number of concurrent polling goroutines")
+	token := fs.String("token", "", "Bearer token to present to the controller (Authorization: Bearer <token>)")
+	fs.Parse(args)
+
+	if *controllerAddr == "" {
+		fmt.Printf("%s[ERROR]%s worker requires -controller\n", ColorRed+ColorBold, ColorReset)
+		os.Exit(1)
+	}
+
+	httpClient = &http.Client{Timeout: 10 * time.Second}
+	config := Config{SecretScanMaxBytes: defaultSecretScanMaxBytes}
+
+	const emptyStreakLimit = 10
+	var emptyStreak int64
+	var wg sync.WaitGroup
+	var claimed int64
+	var reported int64
+
+	for i := 0; i < *threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt64(&emptyStreak) < emptyStreakLimit {
+				req, err := http.NewRequest(http.MethodGet, strings.TrimRight(*controllerAddr, "/")+"/task", nil)
+				if err != nil {
+					time.Sleep(time.Second)
+					continue
+				}
+				req.Header.Set("Authorization", "Bearer "+*token)
+				resp, err := httpClient.Do(req)
+				if err != nil {
+					time.Sleep(time.Second)
+					continue
+				}
+				if resp.StatusCode == http.StatusNoContent {
+					resp.Body.Close()
+					atomic.AddInt64(&emptyStreak, 1)
+					time.Sleep(500 * time.Millisecond)
+					continue
+				}
+				var task distTask
+				err = json.NewDecoder(resp.Body).Decode(&task)
+				resp.Body.Close()
+				if err != nil {
+					continue
+				}
+				atomic.StoreInt64(&emptyStreak, 0)
+				atomic.AddInt64(&claimed, 1)
+
+				url := buildURL(task.Target, task.Word)
+				result, body, err := makeRequestWithUA(url, "GET", getRandomUserAgent(), config)
+				if err != nil || result.StatusCode == 404 {
+					continue
+				}
+				if shouldScanForSecrets(result.ContentType, len(body), config) {
+					if secrets := detectSecrets(body); len(secrets) > 0 {
+						result.SecretFound = true
+						result.SecretTypes = secrets
+						result.SecretClassification = classifySecretMaterial(body, secrets)
+					}
+				}
+				result.Source = "distributed-worker"
+
+				payload, _ := json.Marshal(result)
+				resultReq, err := http.NewRequest(http.MethodPost, strings.TrimRight(*controllerAddr, "/")+"/result", bytes.NewReader(payload))
+				if err != nil {
+					continue
+				}
+				resultReq.Header.Set("Content-Type", "application/json")
+				resultReq.Header.Set("Authorization", "Bearer "+*token)
+				if resp, err := httpClient.Do(resultReq); err == nil {
+					resp.Body.Close()
+					atomic.AddInt64(&reported, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("%s[✓]%s Controller queue drained -- claimed %d task(s), reported %d finding(s)\n", ColorNeonGreen, ColorReset, claimed, reported)
+}
+
+// runAnnotate implements "capsaicin annotate <results.json> -url X -note
+// '...' -tag followup": it enriches a stored scan's results in place with
+// analyst triage state, so notes and tags survive in the same file the
+// diff/report/SARIF subsystems already read -- no separate triage store
+// to keep in sync.
+func runAnnotate(args []string) {
+	if len(args) == 0 {
+		fmt.Printf("%s[ERROR]%s annotate requires a results file, e.g. capsaicin annotate results.json -url <url> -note \"...\"\n", ColorRed+ColorBold, ColorReset)
+		os.Exit(1)
+	}
+	file := args[0]
+	fs := flag.NewFlagSet("annotate", flag.ExitOnError)
+	targetURL := fs.String("url", "", "URL of the result to annotate")
+	note := fs.String("note", "", "Free-text analyst note to attach")
+	tag := fs.String("tag", "", "Tag to attach (e.g. followup, false-positive)")
+	fs.Parse(args[1:])
+
+	if *targetURL == "" {
+		fmt.Printf("%s[ERROR]%s annotate requires -url\n", ColorRed+ColorBold, ColorReset)
+		os.Exit(1)
+	}
+	if *note == "" && *tag == "" {
+		fmt.Printf("%s[ERROR]%s annotate requires -note and/or -tag\n", ColorRed+ColorBold, ColorReset)
+		os.Exit(1)
+	}
+
+	results, err := loadBaseline(file)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to read %s: %s\n", ColorRed+ColorBold, ColorReset, file, err)
+		os.Exit(1)
+	}
+
+	matched := 0
+	for i := range results {
+		if results[i].URL != *targetURL {
+			continue
+		}
+		matched++
+		if *note != "" {
+			results[i].Notes = append(results[i].Notes, fmt.Sprintf("[%s] %s", time.Now().Format(time.RFC3339), *note))
+		}
+		if *tag != "" && !containsString(results[i].Tags, *tag) {
+			results[i].Tags = append(results[i].Tags, *tag)
+		}
+	}
+	if matched == 0 {
+		fmt.Printf("%s[ERROR]%s No result with url %s found in %s\n", ColorRed+ColorBold, ColorReset, *targetURL, file)
+		os.Exit(1)
+	}
+
+	if err := saveResults(results, file); err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to save %s: %s\n", ColorRed+ColorBold, ColorReset, file, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s[✓]%s Annotated %d result(s) for %s in %s\n", ColorNeonGreen, ColorReset, matched, *targetURL, file)
+}
+
+func showBanner() {
+	banner := `
+   ██████╗ █████╗ ██████╗ ███████╗ █████╗ ██╗ ██████╗██╗███╗   ██╗
+  ██╔════╝██╔══██╗██╔══██╗██╔════╝██╔══██╗██║██╔════╝██║████╗  ██║
+  ██║     ███████║██████╔╝███████╗███████║██║██║     ██║██╔██╗ ██║
+  ██║     ██╔══██║██╔═══╝ ╚════██║██╔══██║██║██║     ██║██║╚██╗██║
+  ╚██████╗██║  ██║██║     ███████║██║  ██║██║╚██████╗██║██║ ╚████║
+   ╚═════╝╚═╝  ╚═╝╚═╝     ╚══════╝╚═╝  ╚═╝╚═╝ ╚═════╝╚═╝╚═╝  ╚═══╝
+`
+	subtitle := `
+  ▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄
+  █                      v1.5 RED TEAM EDITION                   █
+  █            Advanced Web Directory Fuzzer + WAF Hunter        █
+  █                     Developer: Hawtsauce                     █
+  █                Intelligence > Speed > Stealth                █
+  ▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀▀
+`
+	fmt.Printf("%s%s%s", ColorMagenta+ColorBold, banner, ColorReset)
+	fmt.Printf("%s%s%s\n", ColorNeonCyan, subtitle, ColorReset)
+}
+
+func showAttackConfig(config Config, targets []string) {
+	fmt.Printf("\n%s╔══ ATTACK CONFIGURATION ══════════════════════════════════════╗%s\n", ColorOrange+ColorBold, ColorReset)
+	fmt.Printf("%s║%s\n", ColorOrange, ColorReset)
+
+	// Targets
+	if len(targets) == 1 {
+		fmt.Printf("%s║%s   🎯 Target:           %s%s%s\n", ColorOrange, ColorReset, ColorBold, targets[0], ColorReset)
+	} else {
+		fmt.Printf("%s║%s   🎯 Targets:         %s%d domains%s\n", ColorOrange, ColorReset, ColorBold, len(targets), ColorReset)
+	}
+
+	// Wordlist
+	fmt.Printf("%s║%s   📝 Wordlist:         %s\n", ColorOrange, ColorReset, config.Wordlist)
+
+	// Threads
+	fmt.Printf("%s║%s   ⚡ Threads:          %s%d%s\n", ColorOrange, ColorReset, ColorBold, config.Threads, ColorReset)
+
+	// Extensions
+	if len(config.Extensions) > 0 {
+		fmt.Printf("%s║%s   📦 Extensions:       %s\n", ColorOrange, ColorReset, strings.Join(config.Extensions, ", "))
+	}
+
+	// Recursive
+	if config.MaxDepth > 0 {
+		fmt.Printf("%s║%s   🔄 Recursive:        %sEnabled (Depth: %d)%s\n", ColorOrange, ColorReset, ColorNeonGreen, config.MaxDepth, ColorReset)
+	}
+
+	// Custom Headers
+	if len(config.CustomHeaders) > 0 {
+		fmt.Printf("%s║%s   🔑 Custom Headers:   %s%d configured%s\n", ColorOrange, ColorReset, ColorBold, len(config.CustomHeaders), ColorReset)
+		for key := range config.CustomHeaders {
+			fmt.Printf("%s║%s      └─ %s\n", ColorOrange, ColorReset, key)
+		}
+	}
+
+	// Modules
+	fmt.Printf("%s║%s\n", ColorOrange, ColorReset)
+	fmt.Printf("%s║%s   %s🛡️  WAF Detection:%s      ACTIVE\n", ColorOrange, ColorReset, ColorMagenta, ColorReset)
+	fmt.Printf("%s║%s   %s🔐 Secret Scanner:%s      ACTIVE (%d patterns)\n", ColorOrange, ColorReset, ColorRed, ColorReset, len(secretPatterns))
+	fmt.Printf("%s║%s   %s🔥 Method Fuzzing:%s     ACTIVE\n", ColorOrange, ColorReset, ColorYellow, ColorReset)
+	fmt.Printf("%s║%s   %s🧠 Smart Calibration:%s  ACTIVE\n", ColorOrange, ColorReset, ColorCyan, ColorReset)
+
+	fmt.Printf("%s║%s\n", ColorOrange, ColorReset)
+	fmt.Printf("%s╚══════════════════════════════════════════════════════════════╝%s\n", ColorOrange+ColorBold, ColorReset)
+}
+
+// preScanFlagValue finds a flag's value by hand, for flags (-config,
+// -profile) whose whole job is to seed other flags' defaults and therefore
+// have to be known before flag.Parse runs the real argument pass
+func preScanFlagValue(args []string, name string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		for _, prefix := range []string{"-" + name + "=", "--" + name + "="} {
+			if strings.HasPrefix(arg, prefix) {
+				return strings.TrimPrefix(arg, prefix)
+			}
+		}
+		if (arg == "-"+name || arg == "--"+name) && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// scanProfiles bundles sensible flag presets for common engagement shapes.
+// Applied as flag defaults before flag.Parse, so any explicit flag --
+// including a -config entry -- still overrides the profile's choice
+var scanProfiles = map[string]map[string]string{
+	"stealth": {
+		"t":               "5",
+		"jitter":          "500ms",
+		"rate-per-target": "1",
+	},
+	"aggressive": {
+		"t":          "200",
+		"aggressive": "true",
+	},
+	"api": {
+		"x":            "json",
+		"content-type": "application/json",
+		"aggressive":   "true",
+	},
+}
+
+// applyScanProfile seeds the named profile's flag values as defaults
+func applyScanProfile(name string) error {
+	profile, ok := scanProfiles[name]
+	if !ok {
+		names := make([]string, 0, len(scanProfiles))
+		for n := range scanProfiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown -profile %q (known: %s)", name, strings.Join(names, ", "))
+	}
+	for flagName, value := range profile {
+		if err := flag.Set(flagName, value); err != nil {
+			return fmt.Errorf("profile %q: failed to set -%s: %w", name, flagName, err)
+		}
+	}
+	return nil
+}
+
+// applyConfigFile loads a YAML-subset config file and seeds each entry as a
+// flag default via flag.Set, called before flag.Parse so a flag explicitly
+// given on the command line still wins. Supports scalar "key: value" lines
+// and list values as indented "- item" lines under a bare "key:", enough to
+// cover repeatable flags like -H and -tag
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	scalars, lists, err := parseYAMLConfig(data)
+	if err != nil {
+		return err
+	}
+	for key, value := range scalars {
+		if err := flag.Set(key, value); err != nil {
+			fmt.Printf("%s[WARN]%s Unknown -config key %q, ignoring\n", ColorYellow, ColorReset, key)
+		}
+	}
+	for key, items := range lists {
+		for _, item := range items {
+			if err := flag.Set(key, item); err != nil {
+				fmt.Printf("%s[WARN]%s Unknown -config key %q, ignoring\n", ColorYellow, ColorReset, key)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseYAMLConfig parses a small, deliberately-not-general subset of YAML --
+// "key: value" scalars and "key:" followed by indented "- item" lists --
+// which is all -config needs to express and avoids pulling in a YAML
+// dependency for a single-file, stdlib-only tool
+func parseYAMLConfig(data []byte) (map[string]string, map[string][]string, error) {
+	scalars := make(map[string]string)
+	lists := make(map[string][]string)
+	lastKey := ""
+
+	for _, rawLine := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(rawLine, "\r")
+		stripped := strings.TrimSpace(line)
+		if stripped == "" || strings.HasPrefix(stripped, "#") {
+			continue
+		}
+
+		indented := line != stripped
+		if indented && strings.HasPrefix(stripped, "- ") {
+			if lastKey == "" {
+				return nil, nil, fmt.Errorf("list item with no preceding key: %q", stripped)
+			}
+			lists[lastKey] = append(lists[lastKey], unquoteYAML(strings.TrimSpace(strings.TrimPrefix(stripped, "- "))))
+			continue
+		}
+
+		parts := strings.SplitN(stripped, ":", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("malformed line (expected \"key: value\"): %q", stripped)
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if value == "" {
+			lastKey = key
+			continue
+		}
+		lastKey = ""
+		scalars[key] = unquoteYAML(value)
+	}
+	return scalars, lists, nil
+}
+
+// unquoteYAML strips a single layer of matching quotes, if present
+func unquoteYAML(s string) string {
+	if len(s) >= 2 {
+		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
+
+func parseFlags() Config {
+	config := Config{
+		CustomHeaders: make(map[string]string),
+	}
+
+	var headers headerFlags
+	var tags tagFlags
+
+	flag.StringVar(&config.TargetURL, "u", "", "Target URL (or use STDIN for multiple targets)")
+	flag.StringVar(&config.Wordlist, "w", "", "Wordlist path (required)")
+	flag.IntVar(&config.Threads, "t", 50, "Number of concurrent threads")
+	extensions := flag.String("x", "", "Extensions (comma-separated, e.g., php,html,txt)")
+	flag.IntVar(&config.Timeout, "timeout", 10, "Request timeout in seconds")
+	flag.StringVar(&config.OutputFile, "o", "", "Output file (JSON format)")
+	flag.StringVar(&config.HTMLReport, "html", "", "Generate HTML report")
+	flag.BoolVar(&config.Verbose, "v", false, "Verbose mode (print every request)")
+	verboseSample := flag.String("v-sample", "100%", `Percentage of requests to print in verbose mode, e.g. "1%" (default 100%)`)
+	traceURL := flag.String("trace-url", "", "Dump full request/response + httptrace timing for URLs matching this regex pattern")
+	flag.DurationVar(&config.RampUp, "ramp-up", 0, `Spread worker startup over this duration, e.g. "60s" (0=disabled, start all threads immediately)`)
+	flag.BoolVar(&config.Stream, "stream", false, `Emit findings as NDJSON to stdout instead of colored console output, for piping into "capsaicin analyze -"`)
+	flag.BoolVar(&config.PreferIPv6, "prefer-ipv6", false, "Prefer IPv6 when dialing dual-stack targets")
+	flag.BoolVar(&config.PreferIPv4, "prefer-ipv4", false, "Prefer IPv4 when dialing dual-stack targets")
+	flag.IntVar(&config.MaxDepth, "depth", 0, "Recursive scanning depth (0=disabled)")
+	flag.Var(&headers, "H", "Custom header (can be used multiple times)")
+	flag.Var(&tags, "tag", `Tag rule "name=substring" applied when body contains substring (repeatable)`)
+	flag.BoolVar(&config.Aggressive, "aggressive", false, "Allow aggressive modules (bypass/mutation/method-fuzz) without interactive confirmation")
+	flag.BoolVar(&config.AllowDestructive, "allow-destructive", false, "Allow PUT/DELETE within -aggressive method fuzzing (excluded by default; automatic DELETE on every 405 has caused real incidents)")
+	flag.StringVar(&config.OutputByStatusDir, "o-by-status", "", "Directory to write per-status-code URL lists (200.txt, 403.txt, ...)")
+	flag.StringVar(&config.WordlistReportFile, "wordlist-report", "", "Write a JSON wordlist efficacy report (hit rate per source/word) to this file")
+	flag.StringVar(&config.BaselineFile, "baseline", "", "Prior scan's JSON output to diff against in the HTML report (new/changed/resolved)")
+	flag.BoolVar(&config.GroupOutput, "group-output", false, "Buffer findings briefly and print them grouped by target then severity (criticals first) instead of strict arrival order")
+	flag.DurationVar(&config.GroupFlush, "group-flush", 2*time.Second, "How often -group-output flushes its buffered findings")
+	flag.StringVar(&config.ExcludeKnownFile, "exclude-known", "", "Prior scan's JSON output; skip URLs already confirmed there so an incremental scan spends its budget on new content")
+	flag.StringVar(&config.CanaryLogFile, "canary-log", "", "Write the registry of canary values used this scan (bypass headers, calibration probes) as JSON, for correlating against server logs later")
+	flag.StringVar(&config.SpoofIP, "spoof-ip", "", "IP address to put in bypass headers (X-Forwarded-For etc) instead of a random private address; set to a specific internal range when an engagement requires testing a particular trust boundary")
+	flag.BoolVar(&config.ProbeUploads, "probe-uploads", false, "On detected upload forms, attempt a harmless canary file upload and check whether it's retrievable unauthenticated (requires -aggressive)")
+	flag.Float64Var(&config.RateLimit, "rate", 0, "Max requests per second across the whole scan (0=unlimited)")
+	flag.Float64Var(&config.RateLimitPerTarget, "rate-per-target", 0, "Max requests per second against any single target (0=unlimited)")
+	flag.DurationVar(&config.Jitter, "jitter", 0, `Sleep a random duration in [0, jitter) before each request, e.g. "250ms", to avoid a metronomic request pattern (0=disabled)`)
+	flag.BoolVar(&config.ValidateSecrets, "validate-secrets", false, "Check discovered credentials against their provider's non-destructive identity endpoint (AWS STS, Slack auth.test, Google tokeninfo) and mark live keys critical")
+	matchCodes := flag.String("mc", "", "Only report these status codes, comma list and ranges, e.g. \"200-299,401\" (overrides the default 2xx/3xx/401/403 heuristic)")
+	filterCodes := flag.String("fc", "", "Never report these status codes, comma list and ranges, e.g. \"404,500-599\" (applied before -mc)")
+	matchSizes := flag.String("ms", "", "Only report responses with this body size in bytes, comma list and ranges, e.g. \"1234,5000-6000\"")
+	filterSizes := flag.String("fs", "", "Never report responses with this body size in bytes, comma list and ranges; use to drop a known boilerplate page size")
+	matchWords := flag.String("mw", "", "Only report responses with this word count, comma list and ranges")
+	filterWords := flag.String("fw", "", "Never report responses with this word count, comma list and ranges")
+	matchLines := flag.String("ml", "", "Only report responses with this line count, comma list and ranges")
+	filterLines := flag.String("fl", "", "Never report responses with this line count, comma list and ranges")
+	matchTypes := flag.String("mt", "", "Only report responses whose Content-Type is one of this comma list, e.g. \"application/json,text/xml\" (trailing slash matches a whole family, e.g. \"image/\")")
+	filterTypes := flag.String("ft", "", "Never report responses whose Content-Type is one of this comma list (applied before -mt)")
+	flag.BoolVar(&config.SPAAware, "spa-aware", false, "Detect single-page apps during calibration (every probe returns the same 200 page) and restrict the wordlist to -spa-prefixes instead of fuzzing a full wordlist of identical responses")
+	spaPrefixes := flag.String("spa-prefixes", "api,graphql,assets,static,js,css", "Comma-separated path prefixes to keep fuzzing once -spa-aware detects a single-page app")
+	flag.BoolVar(&config.CTExpand, "ct-expand", false, "With -dns: also query crt.sh certificate transparency logs for additional names under the target domain, feeding them through the same wildcard-DNS filter before reporting")
+	flag.StringVar(&config.SummaryFile, "summary", "", "Write a summary.json of per-target stats, WAFs/fingerprints seen, module outcomes, and timing, independent of the findings array, for dashboards that don't want to parse every Result")
+	flag.StringVar(&config.HarvestFile, "harvest-file", "", "Collect email addresses and name-like patterns seen on scanned pages into this JSON file, deduplicated per target, for a social-engineering recon phase -- kept separate from the main findings table")
+	flag.IntVar(&config.Retries, "retries", 0, "Retry a request this many times on network errors or a plain 5xx (excluding 429/503, which get their own Retry-After-aware backoff) before counting it as an error")
+	flag.DurationVar(&config.RetryDelay, "retry-delay", 500*time.Millisecond, "Base delay before each retry under -retries, multiplied by the attempt number (simple linear backoff)")
+	flag.StringVar(&config.EvidenceDir, "evidence-dir", "", "Enable evidence mode: for every Critical or secret finding, immediately write the full response body here under its content hash and record a signed, timestamped manifest entry, for legal/remediation disputes")
+	flag.StringVar(&config.EvidenceKeyFile, "evidence-key-file", "", fmt.Sprintf("File holding the hex-encoded HMAC key to sign the evidence manifest with (falls back to %s); generated and printed if neither is set -- keep it out of the evidence bundle itself", evidenceKeyEnvVar))
+	flag.BoolVar(&config.HeadDiffCheck, "head-diff", false, "For every interesting GET result, issue an extra HEAD request and flag a status or drastic length mismatch between the two -- often a sign access control or caching only covers one method")
+	flag.BoolVar(&config.Insecure, "insecure", false, "Skip TLS certificate verification, for internal/self-signed targets")
+	flag.BoolVar(&config.Insecure, "k", false, "Alias for -insecure")
+	flag.StringVar(&config.TLSMinVersion, "tls-min", "", `Minimum TLS version to negotiate: "1.0", "1.1", "1.2", or "1.3" (default: Go's standard minimum)`)
+	flag.StringVar(&config.TLSMaxVersion, "tls-max", "", `Maximum TLS version to negotiate: "1.0", "1.1", "1.2", or "1.3" (default: Go's standard maximum) -- useful for testing legacy endpoints that mishandle newer versions`)
+	flag.StringVar(&config.SNI, "sni", "", "Override the TLS ServerName sent in the ClientHello, independent of the request's Host header")
+	flag.StringVar(&config.TLSCertFile, "cert", "", "Client certificate file for mutual TLS (requires -key)")
+	flag.StringVar(&config.TLSKeyFile, "key", "", "Client private key file for mutual TLS (requires -cert)")
+	flag.BoolVar(&config.HTTP2, "http2", false, "Force-attempt HTTP/2 (disabled by default since the custom dialer/TLS config would otherwise make Go's transport silently fall back to HTTP/1.1)")
+	flag.BoolVar(&config.HTTP3, "http3", false, "Flag targets advertising HTTP/3 support via their Alt-Svc header -- recon only, this tool has no QUIC client to actually fuzz over h3")
+	matchProtos := flag.String("mp", "", `Only report responses negotiated over one of these protocols, comma list, e.g. "HTTP/2.0"`)
+	filterProtos := flag.String("fp", "", "Never report responses negotiated over one of these protocols (applied before -mp)")
+	flag.BoolVar(&config.RetryTimeouts, "retry-timeouts", false, "Queue timed-out requests for a second attempt at -timeout-retry-concurrency once the main scan drains, instead of dropping them as permanent errors -- slow endpoints are often the most interesting ones")
+	flag.IntVar(&config.TimeoutRetryConcurrency, "timeout-retry-concurrency", 2, "Concurrency for the -retry-timeouts end-of-scan pass")
+	requestFile := flag.String("request", "", "Raw Burp-style HTTP request file with FUZZ markers, preserving method, headers and body exactly -- overrides -X/-d/-data/-headers for authenticated or complex requests")
+	ports := flag.String("ports", "", "Comma-separated ports, e.g. \"80,443,8080,8443,3000\": quickly TCP-probe them against every bare-host target and expand responsive ones into full targets, bridging the gap when there's no prior nmap scan")
+	flag.StringVar(&config.CompareBaselineFile, "compare-baseline", "", "Diff this scan's findings against a signed inventory from \"capsaicin baseline\", exiting non-zero for CI if any reachable path is new or has changed")
+	flag.StringVar(&config.BaselineKeyFile, "baseline-key-file", "", fmt.Sprintf("File holding the hex-encoded HMAC key -compare-baseline's inventory was signed with (falls back to %s)", baselineKeyEnvVar))
+	flag.StringVar(&config.LoginConfigFile, "login-config", "", `JSON file describing a login request ({"url","method","body","content_type","token_header","token_format","token_regex"}), executed before scanning and re-executed when a target's responses look like the session expired`)
+	flag.BoolVar(&config.TUI, "tui", false, "Live dashboard (progress bar, counters, recent findings) redrawn in place instead of scrolling console output -- a stdlib-only approximation, no scrolling/filtering or pause/thread-count keybindings")
+	flag.BoolVar(&config.KeyboardControls, "keyboard-controls", false, "Runtime hotkeys in classic (non -tui) mode: p pause/resume, +/- adjust active thread count, s print a stats snapshot, q finish gracefully -- like ffuf's interactive mode. Linux-only")
+	flag.BoolVar(&config.LocalMode, "local", false, "Throttle-free profile for your own loopback/private staging targets: disables UA rotation, -jitter, and WAF-detection overhead and raises the default thread count. Refuses to run against a non-loopback/private target")
+	flag.IntVar(&config.SecretScanMaxBytes, "secret-scan-max-bytes", defaultSecretScanMaxBytes, "Only run full secret detection on textual Content-Types and responses at or under this many bytes")
+	flag.BoolVar(&config.DeepSecrets, "deep-secrets", false, "On every finding, also fetch and scan its linked .js/.json/.txt assets one level deep for additional secret material")
+	notify := flag.String("notify", "", "Comma-separated notification sinks (slack://webhook-path, discord://webhook-path, telegram://token@chat-id) to message on every critical finding and on scan completion")
+	flag.StringVar(&config.MetricsAddr, "metrics-addr", "", `Serve Prometheus-style runtime metrics (goroutines, heap, queue depths) on this address, e.g. ":9090" (disabled by default)`)
+	flag.DurationVar(&config.HealthInterval, "health-interval", 0, "Log a runtime health snapshot (goroutines, heap, queue depths, stuck-worker warnings) to stderr at this interval, for trusting multi-day monitor-mode runs (0=disabled)")
+	matchRegex := flag.String("mr", "", "Only report responses whose body matches this regular expression")
+	filterRegex := flag.String("fr", "", "Never report responses whose body matches this regular expression")
+	flag.StringVar(&config.RequestMethod, "X", "GET", "HTTP method to use for the primary fuzz request")
+	data := flag.String("d", "", "Request body for each request, with FUZZ substituted for the current wordlist entry (implies -X POST if -X is left at the default)")
+	flag.StringVar(data, "data", "", "Alias for -d")
+	dataFile := flag.String("data-file", "", "Read the request body template from this file instead of -d/-data")
+	flag.StringVar(&config.ContentType, "content-type", "", `Content-Type header to send with -d/-data-file (e.g. "application/json")`)
+	flag.IntVar(&config.CalibrationTimeout, "calibration-timeout", 0, "Request timeout in seconds for auto-calibration probes (0=use -timeout)")
+	flag.IntVar(&config.HeavyTimeout, "heavy-timeout", 0, "Request timeout in seconds for heavy follow-up requests, e.g. live secret validation (0=use -timeout)")
+	allowIPs := flag.String("allow-ips", "", "Comma-separated CIDR ranges; refuse to connect to any resolved IP outside them (fails closed)")
+	denyIPs := flag.String("deny-ips", "", "Comma-separated CIDR ranges; refuse to connect to any resolved IP inside them, e.g. production ranges (fails closed)")
+	proxies := flag.String("proxies", "", "Comma-separated proxy URLs (http://, https://, socks5://) to rotate requests through; health-checked and failed over automatically")
+	flag.DurationVar(&config.ProxyHealthInterval, "proxy-health-interval", 30*time.Second, "How often to health-check each -proxies entry")
+	flag.BoolVar(&config.DNSMode, "dns", false, "Subdomain brute-force mode: prepend each wordlist entry to -u as a subdomain and resolve it, instead of path fuzzing")
+	dnsResolvers := flag.String("dns-resolvers", "", "Comma-separated resolver addresses to use for -dns, e.g. \"8.8.8.8:53,1.1.1.1:53\" (default: system resolver)")
+	resolvers := flag.String("resolver", "", "Comma-separated resolver addresses (e.g. \"1.1.1.1:53\") used for every DNS lookup while scanning targets, not just -dns (default: system resolver)")
+	hostsOverride := flag.String("hosts-override", "", `Comma-separated host=ip pairs resolved before -resolver/the system resolver, e.g. "admin.target.com=10.0.0.5", for pre-production hosts and split-horizon DNS`)
+	flag.BoolVar(&config.DNSProbeHTTP, "dns-probe-http", false, "With -dns, also fetch / over HTTP(S) from each discovered subdomain and report its status")
+	flag.String("config", "", "YAML config file holding flag values (targets, wordlist, headers, filters, output, ...); CLI flags override it")
+	flag.String("profile", "", "Named preset bundling sensible flag defaults: stealth, aggressive, api (explicit flags still override it)")
+	flag.StringVar(&config.WAFSignaturesFile, "waf-signatures", "", "JSON file of additional WAFSignature entries (Name, ServerHeader, CustomHeader, CookiePattern, BodyRegex, StatusCode) to extend the built-in knowledge base without recompiling")
+	flag.BoolVar(&config.ProtocolDowngrade, "protocol-downgrade", false, "Aggressive module: retry 400/403-blocked paths over a hand-rolled HTTP/1.0 request with no Host normalization, since some WAFs and legacy backends only enforce rules on HTTP/1.1")
+	flag.StringVar(&config.SARIFReportFile, "sarif", "", "Generate a SARIF 2.1.0 report of critical bypasses, secrets, and PII findings for CI security dashboards (GitHub/GitLab)")
+	flag.BoolVar(&config.VhostMode, "vhost", false, "Virtual-host discovery mode: fuzz the Host header against -u (an IP or bare origin) using the wordlist, then path-fuzz each discovered vhost and attribute findings to it")
+	flag.StringVar(&config.VhostDomain, "vhost-domain", "", "Base domain appended to each wordlist entry to build a candidate Host header, e.g. \"example.com\" + \"admin\" -> \"admin.example.com\" (default: -u's own hostname)")
+	flag.BoolVar(&config.InDocker, "in-docker", false, "Re-exec this scan inside an ephemeral Docker container via the docker CLI, so traffic only exits through -docker-network (e.g. a VPN sidecar); results are copied back to -o on completion")
+	flag.StringVar(&config.DockerImage, "docker-image", "alpine:latest", "Image to run the scan in under -in-docker; only needs to be able to exec a static binary")
+	flag.StringVar(&config.DockerNetwork, "docker-network", "bridge", "Docker --network mode for -in-docker, e.g. \"none\", \"bridge\", or \"container:<vpn-sidecar-name>\" to force egress through a specific interface")
+	flag.BoolVar(&config.RespectRobots, "respect-robots", false, "Fetch robots.txt and skip wordlist paths it disallows (User-agent: * or capsaicin); for polite self-assessment scans of your own production estate")
+	failOn := flag.String("fail-on", "", "Comma-separated finding categories that make the process exit non-zero, for CI gating: secrets, critical, pii, waf, or a status class/code (2xx, 3xx, 4xx, 5xx, 200, ...)")
+	flag.BoolVar(&config.FollowRedirects, "follow-redirects", false, "Manually follow 3xx redirects up to -max-redirects hops, recording the full chain and final URL/status in each result, instead of reporting the first redirect response as-is")
+	flag.IntVar(&config.MaxRedirects, "max-redirects", 5, "Max hops to follow under -follow-redirects; the chain also stops early if a hop's Host leaves -u's scope")
+	calibrationPaths := flag.String("calibration-path", "", "Comma-separated known-missing URLs to use as calibration probes instead of random paths, for apps that rewrite unknown paths to the SPA index rather than 404ing")
+	captureHeaders := flag.String("capture-headers", "", "Comma-separated response header names to store per finding (e.g. Content-Type,Location,WWW-Authenticate), or \"*\" to capture all of them")
+
+	// Custom usage
+	flag.Usage = func() {
+		fmt.Printf("%s", ColorNeonCyan+ColorBold)
+		fmt.Printf(`
+  ╔═══════════════════════════════════════════════════════════════╗
+  ║           CAPSAICIN v1.5 RED TEAM - Usage Guide               ║
+  ╚═══════════════════════════════════════════════════════════════╝
+`)
+		fmt.Printf("%s\n", ColorReset)
+		fmt.Printf("%sREQUIRED FLAGS:%s\n", ColorOrange+ColorBold, ColorReset)
+		fmt.Printf("  -u string       Target URL (or pipe via STDIN)\n")
+		fmt.Printf("  -w string       Path to wordlist file\n\n")
+
+		fmt.Printf("%sOPTIONAL FLAGS:%s\n", ColorOrange+ColorBold, ColorReset)
+		fmt.Printf("  -t int         Concurrent threads (default: 50)\n")
+		fmt.Printf("  -x string       Extensions (comma-separated)\n")
+		fmt.Printf("  -H string       Custom headers (repeatable)\n")
+		fmt.Printf("  --timeout int  Request timeout in seconds (default: 10)\n")
+		fmt.Printf("  --depth int    Recursive scanning depth (0=disabled)\n")
+		fmt.Printf("  -v             Verbose mode\n")
+		fmt.Printf(`  -v-sample pct  Sample a percentage of requests in verbose mode, e.g. "1%%"` + "\n")
+		fmt.Printf("  -trace-url pattern  Dump full request/response + timing for matching URLs\n")
+		fmt.Printf(`  -ramp-up duration  Spread worker startup over this duration, e.g. "60s"` + "\n")
+		fmt.Printf("  -stream        Emit NDJSON findings to stdout instead of colored output\n")
+		fmt.Printf("  -prefer-ipv6   Prefer IPv6 when dialing dual-stack targets\n")
+		fmt.Printf("  -prefer-ipv4   Prefer IPv4 when dialing dual-stack targets\n\n")
+		fmt.Printf("%sPIPE PROTOCOL:%s\n", ColorNeonGreen+ColorBold, ColorReset)
+		fmt.Printf("  # Stream findings into a second instance for deeper, downstream modules\n")
+		fmt.Printf("  capsaicin -u https://target.com -w words.txt -stream | capsaicin analyze -\n\n")
+		fmt.Printf("  -o string       JSON output file\n")
+		fmt.Printf("  --html string  HTML report file\n")
+		fmt.Printf(`  -tag string     Tag rule "name=substring" (repeatable)` + "\n")
+		fmt.Printf("  -aggressive    Skip confirmation for aggressive modules (bypass/mutation/method-fuzz)\n")
+		fmt.Printf("  -o-by-status dir  Write per-status-code URL lists (200.txt, 403.txt, ...)\n")
+		fmt.Printf("  -baseline file Prior scan JSON to diff against in the HTML report\n")
+		fmt.Printf("  -X string       HTTP method for the primary fuzz request (default: GET)\n")
+		fmt.Printf(`  -d, -data string  Request body, FUZZ substituted for the wordlist entry` + "\n")
+		fmt.Printf("  -data-file file  Read the request body template from a file\n")
+		fmt.Printf(`  -content-type string  Content-Type header sent with -d/-data-file` + "\n")
+		fmt.Printf("  -calibration-timeout int  Timeout for calibration probes (default: -timeout)\n")
+		fmt.Printf("  -heavy-timeout int        Timeout for heavy follow-up requests like -validate-secrets (default: -timeout)\n")
+		fmt.Printf("  -allow-ips cidrs  Refuse to connect to any resolved IP outside these CIDR ranges\n")
+		fmt.Printf("  -deny-ips cidrs   Refuse to connect to any resolved IP inside these CIDR ranges\n")
+		fmt.Printf("  -proxies urls     Comma-separated proxy pool (http/https/socks5), health-checked with failover\n")
+		fmt.Printf(`  -proxy-health-interval duration  How often to health-check each proxy (default: 30s)` + "\n\n")
+		fmt.Printf("%sDNS BRUTE-FORCE MODE:%s\n", ColorOrange+ColorBold, ColorReset)
+		fmt.Printf("  -dns              Prepend wordlist entries as subdomains of -u and resolve them\n")
+		fmt.Printf("  -dns-resolvers    Comma-separated resolver addresses (default: system resolver)\n")
+		fmt.Printf("  -dns-probe-http   Also fetch / over HTTP(S) from each discovered subdomain\n\n")
+		fmt.Printf("%sCONFIG FILE:%s\n", ColorOrange+ColorBold, ColorReset)
+		fmt.Printf("  -config file.yaml  Load flag values from a YAML-subset file; CLI flags override it\n")
+		fmt.Printf("                     e.g. \"u: https://target.com\" or \"H:\\n  - \\\"Cookie: a=b\\\"\"\n")
+		fmt.Printf("  -waf-signatures file.json  Extend the built-in WAF/CDN fingerprints without recompiling\n")
+		fmt.Printf("  -profile name      Preset flag bundle: stealth, aggressive, api (flags still override it)\n\n")
+
+		fmt.Printf("%sEXAMPLES:%s\n", ColorNeonGreen+ColorBold, ColorReset)
+		fmt.Printf("  # Basic scan\n")
+		fmt.Printf("  capsaicin -u https://target.com -w wordlist.txt\n\n")
+		fmt.Printf("  # With authentication\n")
+		fmt.Printf("  capsaicin -u https://api.target.com -w words.txt \\\n")
+		fmt.Printf("    -H \"Authorization: Bearer token123\" \\\n")
+		fmt.Printf("    -H \"Cookie: session=abc\"\n\n")
+		fmt.Printf("  # Multi-target scan\n")
+		fmt.Printf("  cat targets.txt | capsaicin -w wordlist.txt -t 100\n\n")
+	}
+
+	// Apply -config's values as flag defaults before parsing the real
+	// command line, so that any flag given on the command line still wins.
+	// -config itself has to be found by hand since the flag package hasn't
+	// parsed anything yet at this point.
+	if profile := preScanFlagValue(os.Args[1:], "profile"); profile != "" {
+		if err := applyScanProfile(profile); err != nil {
+			fmt.Printf("%s[ERROR]%s %s\n", ColorRed+ColorBold, ColorReset, err)
+			os.Exit(1)
+		}
+	}
+	if path := preScanFlagValue(os.Args[1:], "config"); path != "" {
+		if err := applyConfigFile(path); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to load -config %s: %s\n", ColorRed+ColorBold, ColorReset, path, err)
+			os.Exit(1)
+		}
+	}
+
+	flag.Parse()
+
+	// Parse extensions
+	if *extensions != "" {
+		config.Extensions = strings.Split(*extensions, ",")
+		for i := range config.Extensions {
+			config.Extensions[i] = strings.TrimSpace(config.Extensions[i])
+			if !strings.HasPrefix(config.Extensions[i], ".") {
+				config.Extensions[i] = "." + config.Extensions[i]
+			}
+		}
+	}
+
+	// Parse custom headers
+	for _, h := range headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) == 2 {
+			key := strings.TrimSpace(parts[0])
+			value := strings.TrimSpace(parts[1])
+			config.CustomHeaders[key] = value
+		}
+	}
+
+	// Parse trace URL pattern
+	if *traceURL != "" {
+		if re, err := regexp.Compile(*traceURL); err == nil {
+			config.TraceURLPattern = re
+		} else {
+			fmt.Printf("%s[WARN]%s Invalid -trace-url pattern: %s\n", ColorYellow, ColorReset, err)
+		}
+	}
+
+	// Parse verbose sampling rate
+	sampleStr := strings.TrimSuffix(strings.TrimSpace(*verboseSample), "%")
+	if pct, err := strconv.ParseFloat(sampleStr, 64); err == nil {
+		config.VerboseSample = pct
+	} else {
+		config.VerboseSample = 100
+	}
+
+	// Parse tag rules
+	for _, t := range tags {
+		parts := strings.SplitN(t, "=", 2)
+		if len(parts) == 2 {
+			config.TagRules = append(config.TagRules, TagRule{
+				Name:     strings.TrimSpace(parts[0]),
+				Contains: parts[1],
+			})
+		}
+	}
+
+	// Parse body matcher/filter regexes
+	if *matchRegex != "" {
+		if re, err := regexp.Compile(*matchRegex); err == nil {
+			config.MatchBodyRegex = re
+		} else {
+			fmt.Printf("%s[WARN]%s Invalid -mr pattern: %s\n", ColorYellow, ColorReset, err)
+		}
+	}
+	if *filterRegex != "" {
+		if re, err := regexp.Compile(*filterRegex); err == nil {
+			config.FilterBodyRegex = re
+		} else {
+			fmt.Printf("%s[WARN]%s Invalid -fr pattern: %s\n", ColorYellow, ColorReset, err)
+		}
+	}
+
+	// Resolve the request body template: -data-file takes precedence over
+	// -d/-data since a file is awkward to pass inline for large payloads
+	config.RequestBody = *data
+	if *dataFile != "" {
+		contents, err := os.ReadFile(*dataFile)
+		if err != nil {
+			fmt.Printf("%s[WARN]%s Failed to read -data-file %s: %s\n", ColorYellow, ColorReset, *dataFile, err)
+		} else {
+			config.RequestBody = string(contents)
+		}
+	}
+	if config.RequestBody != "" && config.RequestMethod == "GET" {
+		config.RequestMethod = "POST"
+	}
+
+	if config.CalibrationTimeout == 0 {
+		config.CalibrationTimeout = config.Timeout
+	}
+	if config.HeavyTimeout == 0 {
+		config.HeavyTimeout = config.Timeout
+	}
+
+	if *dnsResolvers != "" {
+		for _, r := range strings.Split(*dnsResolvers, ",") {
+			r = strings.TrimSpace(r)
+			if r == "" {
+				continue
+			}
+			if _, _, err := net.SplitHostPort(r); err != nil {
+				r = r + ":53"
+			}
+			config.DNSResolvers = append(config.DNSResolvers, r)
+		}
+	}
+
+	if *proxies != "" {
+		for _, p := range strings.Split(*proxies, ",") {
+			p = strings.TrimSpace(p)
+			if p != "" {
+				config.ProxyURLs = append(config.ProxyURLs, p)
+			}
+		}
+	}
+
+	if *notify != "" {
+		for _, n := range strings.Split(*notify, ",") {
+			n = strings.TrimSpace(n)
+			if n != "" {
+				config.NotifyURLs = append(config.NotifyURLs, n)
+			}
+		}
+	}
+
+	if *resolvers != "" {
+		for _, r := range strings.Split(*resolvers, ",") {
+			r = strings.TrimSpace(r)
+			if r == "" {
+				continue
+			}
+			if _, _, err := net.SplitHostPort(r); err != nil {
+				r = r + ":53"
+			}
+			config.Resolvers = append(config.Resolvers, r)
+		}
+	}
+
+	if *hostsOverride != "" {
+		config.HostsOverride = make(map[string]string)
+		for _, pair := range strings.Split(*hostsOverride, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			host, ip, ok := strings.Cut(pair, "=")
+			if !ok {
+				continue
+			}
+			config.HostsOverride[strings.TrimSpace(host)] = strings.TrimSpace(ip)
+		}
+	}
+
+	if *requestFile != "" {
+		tmpl, err := parseRawRequestFile(*requestFile)
+		if err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to load -request %s: %s\n", ColorRed+ColorBold, ColorReset, *requestFile, err)
+			os.Exit(1)
+		}
+		config.RequestTemplate = tmpl
+	}
+
+	if *ports != "" {
+		for _, p := range strings.Split(*ports, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			port, err := strconv.Atoi(p)
+			if err != nil {
+				fmt.Printf("%s[WARN]%s Invalid -ports entry %q: %s\n", ColorYellow, ColorReset, p, err)
+				continue
+			}
+			config.PortsPreflight = append(config.PortsPreflight, port)
+		}
+	}
+
+	if *calibrationPaths != "" {
+		for _, p := range strings.Split(*calibrationPaths, ",") {
+			p = strings.TrimSpace(p)
+			if p != "" {
+				config.CalibrationPaths = append(config.CalibrationPaths, p)
+			}
+		}
+	}
+
+	if *captureHeaders != "" {
+		for _, h := range strings.Split(*captureHeaders, ",") {
+			h = strings.TrimSpace(h)
+			if h != "" {
+				config.CaptureHeaders = append(config.CaptureHeaders, h)
+			}
+		}
+	}
+
+	if *failOn != "" {
+		for _, category := range strings.Split(*failOn, ",") {
+			category = strings.ToLower(strings.TrimSpace(category))
+			if category != "" {
+				config.FailOn = append(config.FailOn, category)
+			}
+		}
+	}
+
+	// Parse IP scope allowlist/denylist
+	for _, spec := range []struct {
+		name string
+		raw  string
+		dest *[]*net.IPNet
+	}{
+		{"-allow-ips", *allowIPs, &config.AllowIPRanges},
+		{"-deny-ips", *denyIPs, &config.DenyIPRanges},
+	} {
+		if spec.raw == "" {
+			continue
+		}
+		for _, cidr := range strings.Split(spec.raw, ",") {
+			cidr = strings.TrimSpace(cidr)
+			if cidr == "" {
+				continue
+			}
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				fmt.Printf("%s[WARN]%s Invalid %s CIDR %q: %s\n", ColorYellow, ColorReset, spec.name, cidr, err)
+				continue
+			}
+			*spec.dest = append(*spec.dest, ipNet)
+		}
+	}
+
+	// Parse status code / size / word / line matcher and filter lists
+	for _, spec := range []struct {
+		name string
+		raw  string
+		dest *[][2]int
+	}{
+		{"-mc", *matchCodes, &config.MatchCodeRanges},
+		{"-fc", *filterCodes, &config.FilterCodeRanges},
+		{"-ms", *matchSizes, &config.MatchSizeRanges},
+		{"-fs", *filterSizes, &config.FilterSizeRanges},
+		{"-mw", *matchWords, &config.MatchWordRanges},
+		{"-fw", *filterWords, &config.FilterWordRanges},
+		{"-ml", *matchLines, &config.MatchLineRanges},
+		{"-fl", *filterLines, &config.FilterLineRanges},
+	} {
+		if ranges, err := parseIntRanges(spec.raw); err == nil {
+			*spec.dest = ranges
+		} else {
+			fmt.Printf("%s[WARN]%s Invalid %s spec: %s\n", ColorYellow, ColorReset, spec.name, err)
+		}
+	}
+
+	for _, spec := range []struct {
+		raw  string
+		dest *[]string
+	}{
+		{*matchTypes, &config.MatchContentTypes},
+		{*filterTypes, &config.FilterContentTypes},
+		{*matchProtos, &config.MatchProtocols},
+		{*filterProtos, &config.FilterProtocols},
+	} {
+		for _, t := range strings.Split(spec.raw, ",") {
+			t = strings.TrimSpace(t)
+			if t != "" {
+				*spec.dest = append(*spec.dest, t)
+			}
+		}
+	}
+
+	for _, p := range strings.Split(*spaPrefixes, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			config.SPAPrefixes = append(config.SPAPrefixes, p)
+		}
+	}
+
+	return config
+}
+
+// parseIntRanges parses a comma-separated integer spec like "200-299,401"
+// (status codes) or "1000-2000" (sizes, word counts, line counts) into
+// inclusive [min, max] ranges; a bare number N becomes the range [N, N].
+// An empty spec returns a nil slice, meaning "unset"
+func parseIntRanges(spec string) ([][2]int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	var ranges [][2]int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if lo, hi, ok := strings.Cut(part, "-"); ok {
+			min, err := strconv.Atoi(strings.TrimSpace(lo))
+			if err != nil {
+				return nil, fmt.Errorf("bad range %q: %w", part, err)
+			}
+			max, err := strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil {
+				return nil, fmt.Errorf("bad range %q: %w", part, err)
+			}
+			ranges = append(ranges, [2]int{min, max})
+		} else {
+			code, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("bad status code %q: %w", part, err)
+			}
+			ranges = append(ranges, [2]int{code, code})
+		}
+	}
+	return ranges, nil
+}
+
+// matchesIntRanges reports whether code falls within any of ranges
+func matchesIntRanges(code int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPortsPreflight implements -ports: a quick TCP connect probe of each
+// port against every bare-host entry in targets (entries that already
+// specify a scheme are left untouched), expanding each responsive host:port
+// pair into a full http(s) target. This bridges the gap when there's no
+// prior nmap scan to seed the target list from.
+func expandPortsPreflight(targets []string, ports []int, timeout time.Duration) []string {
+	expanded := make([]string, 0, len(targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, target := range targets {
+		if strings.Contains(target, "://") {
+			expanded = append(expanded, target)
+			continue
+		}
+
+		host := target
+		for _, port := range ports {
+			wg.Add(1)
+			go func(host string, port int) {
+				defer wg.Done()
+				addr := net.JoinHostPort(host, strconv.Itoa(port))
+				conn, err := net.DialTimeout("tcp", addr, timeout)
+				if err != nil {
+					return
+				}
+				conn.Close()
+
+				scheme := "http"
+				if port == 443 || port == 8443 {
+					scheme = "https"
+				}
+				url := fmt.Sprintf("%s://%s:%d", scheme, host, port)
+
+				mu.Lock()
+				expanded = append(expanded, url)
+				fmt.Printf("%s[✓]%s %s is open -- expanded into target %s\n", ColorNeonGreen, ColorReset, addr, url)
+				mu.Unlock()
+			}(host, port)
+		}
+	}
+
+	wg.Wait()
+	sort.Strings(expanded)
+	return expanded
+}
+
+func validateConfig(config *Config, targets []string) error {
+	if len(targets) == 0 {
+		return fmt.Errorf("no targets specified")
+	}
+
+	for i := range targets {
+		if !strings.HasPrefix(targets[i], "http://") && !strings.HasPrefix(targets[i], "https://") {
+			targets[i] = "http://" + targets[i]
+		}
+	}
+
+	if config.Wordlist == "" {
+		return fmt.Errorf("wordlist is required (-w)")
+	}
+	if _, err := os.Stat(config.Wordlist); os.IsNotExist(err) {
+		return fmt.Errorf("wordlist file not found: %s", config.Wordlist)
+	}
+	return nil
+}
+
+// NEW FEATURE: Canary safety check - confirm before running aggressive
+// modules (bypass/mutation/method-fuzz) against potentially out-of-scope hosts
+func confirmAggressive(targets []string) bool {
+	fmt.Printf("\n%s[⚠]%s About to run AGGRESSIVE modules (403/401 bypass, method fuzzing, mutations)\n", ColorYellow+ColorBold, ColorReset)
+	fmt.Printf("%s[⚠]%s Against %d target(s). This generates extra, more intrusive requests.\n", ColorYellow+ColorBold, ColorReset, len(targets))
+	fmt.Printf("%sProceed? [y/N]: %s", ColorYellow, ColorReset)
+
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// openAuditLog opens the append-only audit log used to record which
+// aggressive technique was used against which host
+func openAuditLog() error {
+	f, err := os.OpenFile("capsaicin_audit.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	auditFile = f
+	return nil
+}
+
+// logAggressiveUse records a single aggressive-technique invocation
+func logAggressiveUse(host, technique, url string) {
+	recordModuleAttempt(technique)
+	if auditFile == nil {
+		return
+	}
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	fmt.Fprintf(auditFile, "%s\thost=%s\ttechnique=%s\turl=%s\n", time.Now().Format(time.RFC3339), host, technique, url)
+}
+
+// moduleAttempts counts every aggressive-module invocation by technique
+// name (the same names passed to logAggressiveUse), independent of the
+// audit log being open, for the per-module breakdown in -summary
+var moduleAttempts sync.Map // map[string]*int64
+
+func recordModuleAttempt(technique string) {
+	counter, _ := moduleAttempts.LoadOrStore(technique, new(int64))
+	atomic.AddInt64(counter.(*int64), 1)
+}
+
+// moduleKeyForTechnique maps a logAggressiveUse technique name onto the
+// Result.Source it produces, for joining attempt counts against hit
+// counts in the per-module summary. "header-bypass+mutation" covers both
+// the initial bypass attempt and any follow-on mutation probes it
+// triggers, so its attempts are attributed to "bypass"; mutation hits are
+// still counted, just without a matching attempt count of their own.
+func moduleKeyForTechnique(technique string) string {
+	if technique == "header-bypass+mutation" {
+		return "bypass"
+	}
+	return technique
+}
+
+// joinWordExt appends a file extension to a wordlist entry, preserving any
+// query string on the entry (e.g. "admin.php?debug=1") instead of tacking the
+// extension onto the end of the query, which would corrupt parameterized entries
+func joinWordExt(word, ext string) string {
+	if idx := strings.IndexByte(word, '?'); idx != -1 {
+		return word[:idx] + ext + "?" + word[idx+1:]
+	}
+	return word + ext
+}
+
+// buildURL composes a target and a wordlist path into a full request URL via
+// net/url instead of raw string concatenation, so base paths (https://host/app/),
+// ports and existing query strings on the target are preserved rather than mangled
+func buildURL(targetURL, path string) string {
+	base, err := url.Parse(targetURL)
+	if err != nil {
+		return strings.TrimSuffix(targetURL, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	pathPart, query := path, ""
+	if idx := strings.IndexByte(path, '?'); idx != -1 {
+		pathPart, query = path[:idx], path[idx+1:]
+	}
+
+	joined := base.JoinPath(pathPart)
+	if query != "" {
+		if joined.RawQuery != "" {
+			joined.RawQuery += "&" + query
+		} else {
+			joined.RawQuery = query
+		}
+	}
+
+	return joined.String()
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// buildTLSConfig translates -insecure/-tls-min/-sni/-cert/-key into a
+// tls.Config for the shared transport, so internal targets with self-signed
+// certs, legacy TLS versions, SNI-based virtual hosting, or mutual-TLS auth
+// can still be scanned.
+func buildTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.Insecure}
+
+	if config.TLSMinVersion != "" {
+		version, ok := tlsVersions[config.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown -tls-min value %q (expected one of 1.0, 1.1, 1.2, 1.3)", config.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if config.TLSMaxVersion != "" {
+		version, ok := tlsVersions[config.TLSMaxVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown -tls-max value %q (expected one of 1.0, 1.1, 1.2, 1.3)", config.TLSMaxVersion)
+		}
+		tlsConfig.MaxVersion = version
+	}
+
+	if config.SNI != "" {
+		tlsConfig.ServerName = config.SNI
+	}
+
+	if config.TLSCertFile != "" || config.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load -cert/-key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// rawRequestTemplate is a parsed -request file: a Burp-style raw HTTP
+// request (request line, headers, and body) sent verbatim except for
+// "FUZZ" substitution in the path, header values, and body.
+type rawRequestTemplate struct {
+	Method string
+	Path   string
+	Host   string
+	Header http.Header
+	Body   string
+}
+
+// parseRawRequestFile reads a raw HTTP request file for -request, using
+// http.ReadRequest so the request-line and header parsing follow the same
+// wire format Burp's "Copy as raw request" / a saved request file already
+// produces, instead of hand-rolling a second parser for it.
+func parseRawRequestFile(path string) (*rawRequestTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n", "\r\n")
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(normalized)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse raw request: %w", err)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw request body: %w", err)
+	}
+
+	return &rawRequestTemplate{
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Host:   req.Host,
+		Header: req.Header,
+		Body:   string(body),
+	}, nil
+}
+
+// makeRequestFromTemplate sends a -request raw request template against
+// targetBase with "FUZZ" replaced by word everywhere it appears, preserving
+// the template's method, headers, and body exactly otherwise.
+func makeRequestFromTemplate(tmpl *rawRequestTemplate, targetBase, userAgent, word string, config Config) (*Result, string, error) {
+	fullURL := buildURL(targetBase, strings.ReplaceAll(tmpl.Path, "FUZZ", word))
+	body := strings.ReplaceAll(tmpl.Body, "FUZZ", word)
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(tmpl.Method, fullURL, bodyReader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for name, values := range tmpl.Header {
+		for _, v := range values {
+			req.Header.Add(name, strings.ReplaceAll(v, "FUZZ", word))
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if tmpl.Host != "" {
+		req.Host = strings.ReplaceAll(tmpl.Host, "FUZZ", word)
+	}
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+	applyLoginToken(req)
+
+	requestStart := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	responseTime := time.Since(requestStart)
+	bodyContent := string(respBody)
+	tlsVersion, tlsCipher := tlsConnectionInfo(resp)
+	rlLimit, rlRemaining, rlReset := rateLimitHeaders(resp.Header)
+
+	result := &Result{
+		URL:                fullURL,
+		StatusCode:         resp.StatusCode,
+		Size:               len(respBody),
+		WordCount:          len(strings.Fields(bodyContent)),
+		LineCount:          strings.Count(bodyContent, "\n") + 1,
+		Method:             tmpl.Method,
+		Timestamp:          time.Now().Format(time.RFC3339),
+		Server:             resp.Header.Get("Server"),
+		PoweredBy:          resp.Header.Get("X-Powered-By"),
+		UserAgent:          userAgent,
+		Protocol:           resp.Proto,
+		AltSvc:             resp.Header.Get("Alt-Svc"),
+		TLSVersion:         tlsVersion,
+		TLSCipher:          tlsCipher,
+		RateLimitLimit:     rlLimit,
+		RateLimitRemaining: rlRemaining,
+		RateLimitReset:     rlReset,
+		ContentHash:        sha256Hex(respBody),
+		simHash:            simhash64(respBody),
+		ResponseTimeMs:     responseTime.Milliseconds(),
+	}
+
+	if isRedirectStatus(resp.StatusCode) {
+		result.RedirectLocation = resp.Header.Get("Location")
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		result.ContentType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+
+	return result, bodyContent, nil
+}
+
+// loginConfig describes a -login-config scripted login request: the request
+// to send, and the regex used to pull the session token out of its response
+// so it can be injected into every subsequent request
+type loginConfig struct {
+	URL         string `json:"url"`
+	Method      string `json:"method"`
+	Body        string `json:"body"`
+	ContentType string `json:"content_type"`
+	TokenHeader string `json:"token_header"`
+	TokenFormat string `json:"token_format"`
+	TokenRegex  string `json:"token_regex"`
+}
+
+// parseLoginConfigFile reads -login-config's JSON file and fills in its
+// defaults (POST, Authorization header, bare token format)
+func parseLoginConfigFile(path string) (*loginConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login config: %w", err)
+	}
+	var cfg loginConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse login config: %w", err)
+	}
+	if cfg.URL == "" || cfg.TokenRegex == "" {
+		return nil, fmt.Errorf(`login config requires at least "url" and "token_regex"`)
+	}
+	if cfg.Method == "" {
+		cfg.Method = "POST"
+	}
+	if cfg.TokenHeader == "" {
+		cfg.TokenHeader = "Authorization"
+	}
+	if cfg.TokenFormat == "" {
+		cfg.TokenFormat = "%s"
+	}
+	return &cfg, nil
+}
+
+// performLogin executes a -login-config request and extracts the session
+// token from its response body with TokenRegex's first capture group,
+// returning the fully-formatted header value to inject into later requests
+func performLogin(cfg *loginConfig, config Config) (string, error) {
+	re, err := regexp.Compile(cfg.TokenRegex)
+	if err != nil {
+		return "", fmt.Errorf("invalid token_regex: %w", err)
+	}
+
+	var bodyReader io.Reader
+	if cfg.Body != "" {
+		bodyReader = strings.NewReader(cfg.Body)
+	}
+	req, err := http.NewRequest(cfg.Method, cfg.URL, bodyReader)
+	if err != nil {
+		return "", fmt.Errorf("failed to build login request: %w", err)
+	}
+	if cfg.ContentType != "" {
+		req.Header.Set("Content-Type", cfg.ContentType)
+	}
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("login request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read login response: %w", err)
+	}
+
+	matches := re.FindSubmatch(body)
+	if len(matches) < 2 {
+		return "", fmt.Errorf("token_regex did not match login response (status %d)", resp.StatusCode)
+	}
+
+	return fmt.Sprintf(cfg.TokenFormat, string(matches[1])), nil
+}
+
+// loginTokenHeaderName and loginToken hold the -login-config state shared
+// across workers: the header to inject and the most recently extracted
+// token. Using an atomic.Value instead of mutating config.CustomHeaders
+// lets session-refresh update the token without synchronizing with
+// in-flight requests that only ever read it
+var loginTokenHeaderName string
+var loginToken atomic.Value
+
+// applyLoginToken sets the -login-config token header on req, if a login
+// has run and produced one
+func applyLoginToken(req *http.Request) {
+	if loginTokenHeaderName == "" {
+		return
+	}
+	if token, ok := loginToken.Load().(string); ok && token != "" {
+		req.Header.Set(loginTokenHeaderName, token)
+	}
+}
+
+// activeLoginConfig is the parsed -login-config, kept around so a
+// mid-scan session-expiry detection can re-run the same login
+var activeLoginConfig *loginConfig
+
+// loginRefreshMutex serializes refreshLogin so a wall of session-expired
+// redirects across many workers triggers one re-login, not a stampede
+var loginRefreshMutex sync.Mutex
+
+// refreshLogin executes activeLoginConfig and stores the resulting token,
+// used both for the initial login and for later session-expiry refreshes
+func refreshLogin(config Config) error {
+	loginRefreshMutex.Lock()
+	defer loginRefreshMutex.Unlock()
+
+	token, err := performLogin(activeLoginConfig, config)
+	if err != nil {
+		return err
+	}
+	loginTokenHeaderName = activeLoginConfig.TokenHeader
+	loginToken.Store(token)
+	return nil
+}
+
+// sessionExpiryStreak is the number of consecutive login-redirect responses
+// from a target required before -login-config triggers a session refresh
+const sessionExpiryStreak = 5
+
+// targetLoginStreak tracks, per target, how many consecutive responses have
+// redirected to what looks like a login page, for session-expiry detection
+var targetLoginStreak sync.Map // map[string]*int64
+
+// looksLikeLoginRedirect reports whether a 3xx response's Location header
+// points back at a login page -- the signal -login-config watches for to
+// detect that a session has expired mid-scan
+func looksLikeLoginRedirect(statusCode int, location string) bool {
+	if !isRedirectStatus(statusCode) || location == "" {
+		return false
+	}
+	lower := strings.ToLower(location)
+	return strings.Contains(lower, "login") || strings.Contains(lower, "signin")
+}
+
+// recordLoginRedirect tracks consecutive login-redirects per target,
+// returning true the moment the streak crosses sessionExpiryStreak so the
+// caller can trigger a single session refresh rather than one per request
+// in the wall of redirects
+func recordLoginRedirect(target string, isLoginRedirect bool) bool {
+	if !isLoginRedirect {
+		targetLoginStreak.Delete(target)
+		return false
+	}
+	counter, _ := targetLoginStreak.LoadOrStore(target, new(int64))
+	if atomic.AddInt64(counter.(*int64), 1) >= sessionExpiryStreak {
+		targetLoginStreak.Delete(target)
+		return true
+	}
+	return false
+}
+
+// firstHeader returns the first non-empty header value among names, for
+// de facto standards where different APIs use different header casing/names
+// for the same thing (e.g. X-RateLimit-Limit vs RateLimit-Limit)
+func firstHeader(h http.Header, names ...string) string {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// rateLimitHeaders extracts the de facto X-RateLimit-*/RateLimit-* header
+// trio (Limit, Remaining, Reset) that most rate-limited APIs expose under
+// one of a few common header name variants
+func rateLimitHeaders(h http.Header) (limit, remaining, reset string) {
+	limit = firstHeader(h, "X-RateLimit-Limit", "X-Rate-Limit-Limit", "RateLimit-Limit")
+	remaining = firstHeader(h, "X-RateLimit-Remaining", "X-Rate-Limit-Remaining", "RateLimit-Remaining")
+	reset = firstHeader(h, "X-RateLimit-Reset", "X-Rate-Limit-Reset", "RateLimit-Reset")
+	return
+}
+
+// tlsConnectionInfo extracts the negotiated TLS version and cipher suite
+// name from a response, empty for a plain http:// result
+func tlsConnectionInfo(resp *http.Response) (version, cipher string) {
+	if resp.TLS == nil {
+		return "", ""
+	}
+	return tls.VersionName(resp.TLS.Version), tls.CipherSuiteName(resp.TLS.CipherSuite)
+}
+
+// isDeprecatedTLSVersion flags TLS 1.0/1.1, both officially deprecated
+// (RFC 8996) and still accepted by plenty of legacy internal endpoints
+func isDeprecatedTLSVersion(version string) bool {
+	return version == "TLS 1.0" || version == "TLS 1.1"
+}
+
+// isWeakCipher reports whether name is one of Go's own
+// tls.InsecureCipherSuites() (RC4, 3DES, and other ciphers with known
+// practical attacks), rather than maintaining a second hand-rolled list
+func isWeakCipher(name string) bool {
+	for _, cs := range tls.InsecureCipherSuites() {
+		if cs.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dialContextFor returns a dial function honoring -prefer-ipv6/-prefer-ipv4
+// for dual-stack targets: the preferred address family is tried first, with
+// a fallback to the other family so single-stack targets still connect
+func dialContextFor(config Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	// Proxy pool routing supersedes the direct-dial paths below: once a
+	// request is tunneled through a proxy, IP-version preference and
+	// allow/deny-IP scope checks would apply to the proxy's address rather
+	// than the real target, so they're skipped rather than silently
+	// misapplied
+	if len(proxyPool) > 0 {
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			proxy := pickProxy()
+			if proxy == nil {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			}
+			conn, err := dialThroughProxy(ctx, proxy, addr)
+			if err != nil {
+				atomic.AddInt64(&proxy.Failures, 1)
+				return nil, err
+			}
+			return conn, nil
+		}
+	}
+
+	dialer := &net.Dialer{}
+
+	// The allow/deny-IP scope check is wired in as a Control hook rather
+	// than a wrapping layer that re-resolves addr itself: Control fires
+	// after the dialer (and everything layered below -- hosts-override,
+	// -resolver, -prefer-ipv6/-prefer-ipv4) has already turned addr into
+	// the concrete IP it's about to connect() on, so the check sees
+	// exactly what will be dialed instead of racing a second, independent
+	// resolution that could disagree with it
+	if len(config.AllowIPRanges) > 0 || len(config.DenyIPRanges) > 0 {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				host = address
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("scope check: %q did not resolve to an IP before connect", host)
+			}
+			return checkIPScope(ip, config)
+		}
+	}
+
+	dial := dialer.DialContext
+	if config.PreferIPv6 || config.PreferIPv4 {
+		preferred, fallback := "tcp6", "tcp4"
+		if config.PreferIPv4 {
+			preferred, fallback = "tcp4", "tcp6"
+		}
+		dial = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, preferred, addr)
+			if err == nil {
+				return conn, nil
+			}
+			return dialer.DialContext(ctx, fallback, addr)
+		}
+	}
+
+	if len(config.HostsOverride) > 0 || len(config.Resolvers) > 0 {
+		resolver := net.DefaultResolver
+		if len(config.Resolvers) > 0 {
+			resolver = newDNSResolver(config.Resolvers)
+		}
+		inner := dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				host, port = addr, ""
+			}
+			if override, ok := config.HostsOverride[host]; ok {
+				host = override
+			} else if len(config.Resolvers) > 0 {
+				if ips, err := resolver.LookupIPAddr(ctx, host); err == nil && len(ips) > 0 {
+					host = ips[0].IP.String()
+				}
+			}
+			if port != "" {
+				addr = net.JoinHostPort(host, port)
+			} else {
+				addr = host
+			}
+			return inner(ctx, network, addr)
+		}
+	}
+
+	return dial
+}
+
+// checkIPScope fails closed: if an allowlist is configured, an IP outside
+// every allowed range is refused; independently, any IP inside a denylist
+// range (e.g. production CIDRs) is always refused. This runs before the
+// connection is made, not after, so a misconfigured scan can't leak a single
+// request outside its authorized engagement scope
+func checkIPScope(ip net.IP, config Config) error {
+	for _, deny := range config.DenyIPRanges {
+		if deny.Contains(ip) {
+			return fmt.Errorf("refusing to connect: %s is within denied range %s", ip, deny)
+		}
+	}
+	if len(config.AllowIPRanges) == 0 {
+		return nil
+	}
+	for _, allow := range config.AllowIPRanges {
+		if allow.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to connect: %s is outside every allowed range", ip)
+}
+
+// initProxyPool parses -proxies into the package-level rotation pool,
+// marking every entry healthy until the first health check proves otherwise
+func initProxyPool(rawURLs []string) []*proxyEntry {
+	pool := make([]*proxyEntry, 0, len(rawURLs))
+	for _, raw := range rawURLs {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" {
+			fmt.Printf("%s[WARN]%s Invalid proxy URL %q, skipping\n", ColorYellow, ColorReset, raw)
+			continue
+		}
+		pool = append(pool, &proxyEntry{URL: parsed, Healthy: 1})
+	}
+	return pool
+}
+
+// pickProxy round-robins across currently healthy proxies so a single dead
+// proxy doesn't absorb its share of traffic; if every proxy is unhealthy it
+// falls back to round-robining the full pool rather than stalling the scan
+func pickProxy() *proxyEntry {
+	if len(proxyPool) == 0 {
+		return nil
+	}
+	healthy := make([]*proxyEntry, 0, len(proxyPool))
+	for _, p := range proxyPool {
+		if atomic.LoadInt32(&p.Healthy) == 1 {
+			healthy = append(healthy, p)
+		}
+	}
+	pool := proxyPool
+	if len(healthy) > 0 {
+		pool = healthy
+	}
+	idx := atomic.AddInt64(&proxyRRIndex, 1)
+	return pool[int(idx)%len(pool)]
+}
+
+// watchProxyHealth periodically probes every proxy in the pool with a plain
+// TCP dial, removing unreachable ones from rotation and re-admitting them as
+// soon as they answer again, so a transient outage on one proxy degrades
+// throughput rather than silently failing every request routed through it
+func watchProxyHealth(pool []*proxyEntry, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, proxy := range pool {
+			atomic.AddInt64(&proxy.Checks, 1)
+			conn, err := net.DialTimeout("tcp", proxy.URL.Host, 5*time.Second)
+			wasHealthy := atomic.LoadInt32(&proxy.Healthy) == 1
+			if err != nil {
+				atomic.AddInt64(&proxy.Failures, 1)
+				atomic.StoreInt32(&proxy.Healthy, 0)
+				if wasHealthy {
+					fmt.Fprintf(os.Stderr, "%s[PROXY]%s %s marked unhealthy: %s\n", ColorYellow, ColorReset, proxy.URL.Redacted(), err)
+				}
+				continue
+			}
+			conn.Close()
+			atomic.StoreInt32(&proxy.Healthy, 1)
+			if !wasHealthy {
+				fmt.Fprintf(os.Stderr, "%s[PROXY]%s %s recovered\n", ColorNeonGreen, ColorReset, proxy.URL.Redacted())
+			}
+		}
+	}
+}
+
+// printProxyHealthReport prints per-proxy health/usage stats at scan end
+func printProxyHealthReport(pool []*proxyEntry) {
+	if len(pool) == 0 {
+		return
+	}
+	fmt.Printf("%s┌─ PROXY POOL REPORT ──────────────────────────────────────────┐%s\n", ColorCyan, ColorReset)
+	for _, p := range pool {
+		status := fmt.Sprintf("%s✓ healthy%s", ColorNeonGreen, ColorReset)
+		if atomic.LoadInt32(&p.Healthy) == 0 {
+			status = fmt.Sprintf("%s✗ unhealthy%s", ColorRed, ColorReset)
+		}
+		fmt.Printf("%s│%s %-40s %s  (checks: %d, failures: %d)\n",
+			ColorCyan, ColorReset, p.URL.Redacted(), status, atomic.LoadInt64(&p.Checks), atomic.LoadInt64(&p.Failures))
+	}
+	fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+}
+
+// dialThroughProxy tunnels a connection to addr via proxy, using an HTTP(S)
+// CONNECT handshake or a SOCKS5 handshake depending on the proxy's scheme.
+// The returned conn is a raw tunnel: Transport layers TLS on top of it
+// itself for https targets, exactly as if it had dialed addr directly
+func dialThroughProxy(ctx context.Context, proxy *proxyEntry, addr string) (net.Conn, error) {
+	switch proxy.URL.Scheme {
+	case "socks5":
+		return dialSOCKS5(ctx, proxy.URL.Host, addr)
+	case "https":
+		conn, err := (&tls.Dialer{}).DialContext(ctx, "tcp", proxy.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+		return httpConnectTunnel(conn, addr)
+	default: // "http"
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxy.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+		return httpConnectTunnel(conn, addr)
+	}
+}
+
+// httpConnectTunnel issues a CONNECT request over an already-established
+// connection to an HTTP or HTTPS proxy and returns the tunnel on success
+func httpConnectTunnel(conn net.Conn, addr string) (net.Conn, error) {
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", addr, addr)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT to %s failed: %s", addr, resp.Status)
+	}
+	return conn, nil
+}
+
+// dialSOCKS5 performs an unauthenticated SOCKS5 CONNECT handshake (RFC 1928)
+// against proxyAddr, tunneling to addr
+func dialSOCKS5(ctx context.Context, proxyAddr, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte{0x05, 0x01, 0x00}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if greeting[0] != 0x05 || greeting[1] != 0x00 {
+		conn.Close()
+		return nil, fmt.Errorf("socks5 proxy %s: no acceptable auth method", proxyAddr)
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	req := []byte{0x05, 0x01, 0x00, 0x03}
+	req = append(req, byte(len(host)))
+	req = append(req, []byte(host)...)
+	req = append(req, byte(port>>8), byte(port&0xff))
+	if _, err := conn.Write(req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if header[1] != 0x00 {
+		conn.Close()
+		return nil, fmt.Errorf("socks5 proxy %s: connect to %s failed, code %d", proxyAddr, addr, header[1])
+	}
+
+	var skip int
+	switch header[3] {
+	case 0x01: // IPv4
+		skip = 4 + 2
+	case 0x04: // IPv6
+		skip = 16 + 2
+	case 0x03: // domain name
+		lenBuf := make([]byte, 1)
+		if _, err := io.ReadFull(conn, lenBuf); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		skip = int(lenBuf[0]) + 2
+	}
+	if skip > 0 {
+		if _, err := io.ReadFull(conn, make([]byte, skip)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// subdomainResult is one live hostname found by -dns brute-forcing
+type subdomainResult struct {
+	Subdomain  string
+	IPs        []string
+	HTTPStatus int // 0 if -dns-probe-http was not requested or the probe failed
+	HTTPTitle  string
+}
+
+// newDNSResolver builds a resolver that round-robins across -dns-resolvers,
+// or the system resolver if none were given
+func newDNSResolver(resolverAddrs []string) *net.Resolver {
+	if len(resolverAddrs) == 0 {
+		return net.DefaultResolver
+	}
+	var idx int64
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			addr := resolverAddrs[int(atomic.AddInt64(&idx, 1))%len(resolverAddrs)]
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// detectWildcardDNS resolves a random, almost-certainly-unregistered
+// subdomain of domain; if it resolves anyway, the zone has wildcard DNS and
+// every brute-force hit landing on the same IP set is a false positive
+func detectWildcardDNS(resolver *net.Resolver, domain string) []string {
+	probe := randomHex(16) + "." + domain
+	ips, err := resolver.LookupHost(context.Background(), probe)
+	if err != nil {
+		return nil
+	}
+	return ips
+}
+
+// probeSubdomainHTTP fetches / from a discovered subdomain over HTTPS,
+// falling back to HTTP, and extracts the page title for quick triage
+func probeSubdomainHTTP(subdomain string, config Config) (int, string) {
+	for _, scheme := range []string{"https", "http"} {
+		result, body, err := makeRequestWithUA(scheme+"://"+subdomain+"/", "GET", getRandomUserAgent(), config)
+		if err != nil {
+			continue
+		}
+		title := ""
+		if m := titleRegex.FindStringSubmatch(body); len(m) > 1 {
+			title = strings.TrimSpace(m[1])
+		}
+		return result.StatusCode, title
+	}
+	return 0, ""
+}
+
+// runDNSBruteForce implements -dns: it prepends each wordlist entry as a
+// subdomain of domain, resolves it through a pool of workers, and discards
+// hits that are indistinguishable from the zone's wildcard DNS response
+func runDNSBruteForce(config Config, domain string, words []string) []subdomainResult {
+	resolver := newDNSResolver(config.DNSResolvers)
+
+	wildcardIPs := detectWildcardDNS(resolver, domain)
+	wildcardSet := make(map[string]bool, len(wildcardIPs))
+	for _, ip := range wildcardIPs {
+		wildcardSet[ip] = true
+	}
+	if len(wildcardIPs) > 0 {
+		fmt.Printf("%s[WARN]%s Wildcard DNS detected for *.%s -> %s; matching results are filtered\n",
+			ColorYellow, ColorReset, domain, strings.Join(wildcardIPs, ", "))
+	}
+
+	jobs := make(chan string, config.Threads*2)
+	found := make(chan subdomainResult, config.Threads*2)
+
+	var workerWG sync.WaitGroup
+	for i := 0; i < config.Threads; i++ {
+		workerWG.Add(1)
+		go func() {
+			defer workerWG.Done()
+			for word := range jobs {
+				fqdn := word + "." + domain
+				ips, err := resolver.LookupHost(context.Background(), fqdn)
+				if err != nil || len(ips) == 0 {
+					continue
+				}
+				allWildcard := len(wildcardSet) > 0
+				for _, ip := range ips {
+					if !wildcardSet[ip] {
+						allWildcard = false
+						break
+					}
+				}
+				if allWildcard {
+					continue
+				}
+
+				sub := subdomainResult{Subdomain: fqdn, IPs: ips}
+				if config.DNSProbeHTTP {
+					sub.HTTPStatus, sub.HTTPTitle = probeSubdomainHTTP(fqdn, config)
+				}
+				found <- sub
+			}
+		}()
+	}
+
+	go func() {
+		for _, word := range words {
+			jobs <- word
+		}
+		close(jobs)
+	}()
+	go func() {
+		workerWG.Wait()
+		close(found)
+	}()
+
+	var results []subdomainResult
+	for sub := range found {
+		results = append(results, sub)
+		if sub.HTTPStatus > 0 {
+			fmt.Printf("%s[DNS]%s %s -> %s  %s(HTTP %d%s)%s\n", ColorNeonGreen, ColorReset, sub.Subdomain,
+				strings.Join(sub.IPs, ", "), ColorCyan, sub.HTTPStatus, dnsTitleSuffix(sub.HTTPTitle), ColorReset)
+		} else {
+			fmt.Printf("%s[DNS]%s %s -> %s\n", ColorNeonGreen, ColorReset, sub.Subdomain, strings.Join(sub.IPs, ", "))
+		}
+	}
+	if config.CTExpand {
+		results = expandFromCT(results, domain, wildcardSet, config)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Subdomain < results[j].Subdomain })
+	return results
+}
+
+// crtShEntry is the subset of crt.sh's JSON output we care about; name_value
+// is frequently a newline-separated block of every SAN on that certificate
+type crtShEntry struct {
+	NameValue string `json:"name_value"`
+}
+
+// queryCertTransparency asks crt.sh for every name it has seen on a
+// certificate under domain, deduplicated and stripped of the wildcard prefix
+func queryCertTransparency(domain string, config Config) ([]string, error) {
+	req, err := http.NewRequest("GET", "https://crt.sh/?q=%25."+domain+"&output=json", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", getRandomUserAgent())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []crtShEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		for _, line := range strings.Split(entry.NameValue, "\n") {
+			name := strings.ToLower(strings.TrimSpace(line))
+			name = strings.TrimPrefix(name, "*.")
+			if name == "" || name == domain || !strings.HasSuffix(name, "."+domain) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// expandFromCT augments a -dns run's results with names pulled from
+// certificate transparency logs, resolving and wildcard-filtering each one
+// exactly like a brute-forced subdomain so CT expansion can't explode a scan
+// with a zone's wildcard DNS flooding the result set
+func expandFromCT(results []subdomainResult, domain string, wildcardSet map[string]bool, config Config) []subdomainResult {
+	names, err := queryCertTransparency(domain, config)
+	if err != nil {
+		fmt.Printf("%s[WARN]%s -ct-expand query failed: %s\n", ColorYellow, ColorReset, err)
+		return results
+	}
+
+	existing := make(map[string]bool, len(results))
+	for _, r := range results {
+		existing[r.Subdomain] = true
+	}
+
+	resolver := newDNSResolver(config.DNSResolvers)
+	for _, name := range names {
+		if existing[name] {
+			continue
+		}
+		ips, err := resolver.LookupHost(context.Background(), name)
+		if err != nil || len(ips) == 0 {
+			continue
+		}
+		allWildcard := len(wildcardSet) > 0
+		for _, ip := range ips {
+			if !wildcardSet[ip] {
+				allWildcard = false
+				break
+			}
+		}
+		if allWildcard {
+			continue
+		}
+
+		sub := subdomainResult{Subdomain: name, IPs: ips}
+		if config.DNSProbeHTTP {
+			sub.HTTPStatus, sub.HTTPTitle = probeSubdomainHTTP(name, config)
+		}
+		fmt.Printf("%s[CT]%s %s -> %s\n", ColorNeonGreen, ColorReset, sub.Subdomain, strings.Join(sub.IPs, ", "))
+		results = append(results, sub)
+		existing[name] = true
+	}
+	return results
+}
+
+// dnsTitleSuffix formats an optional page title for the -dns-probe-http log line
+func dnsTitleSuffix(title string) string {
+	if title == "" {
+		return ""
+	}
+	return ", " + title
+}
+
+// makeRequestWithHost is makeRequestWithUA but overrides the wire Host
+// header while still connecting to url's own address - the basis for
+// -vhost virtual-host discovery and the combined vhost+path fuzz pass
+func makeRequestWithHost(url, hostOverride, userAgent string, config Config) (*Result, string, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Host = hostOverride
+	req.Header.Set("User-Agent", userAgent)
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+	applyLoginToken(req)
+
+	requestStart := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	responseTime := time.Since(requestStart)
+	bodyContent := string(body)
+	tlsVersion, tlsCipher := tlsConnectionInfo(resp)
+	rlLimit, rlRemaining, rlReset := rateLimitHeaders(resp.Header)
+
+	result := &Result{
+		URL:                url,
+		StatusCode:         resp.StatusCode,
+		Size:               len(body),
+		WordCount:          len(strings.Fields(bodyContent)),
+		LineCount:          strings.Count(bodyContent, "\n") + 1,
+		Method:             "GET",
+		Timestamp:          time.Now().Format(time.RFC3339),
+		Server:             resp.Header.Get("Server"),
+		PoweredBy:          resp.Header.Get("X-Powered-By"),
+		UserAgent:          userAgent,
+		Protocol:           resp.Proto,
+		AltSvc:             resp.Header.Get("Alt-Svc"),
+		TLSVersion:         tlsVersion,
+		TLSCipher:          tlsCipher,
+		RateLimitLimit:     rlLimit,
+		RateLimitRemaining: rlRemaining,
+		RateLimitReset:     rlReset,
+		Vhost:              hostOverride,
+		ResponseTimeMs:     responseTime.Milliseconds(),
+	}
+
+	return result, bodyContent, nil
+}
+
+// runVhostScan implements -vhost: it fuzzes the Host header against
+// targetURL (typically a bare IP) using words as candidate subdomains of
+// domain, discarding hits indistinguishable from a random nonexistent
+// vhost's baseline response, then path-fuzzes every discovered vhost with
+// the same wordlist so findings are attributed to the vhost that served them
+func runVhostScan(config Config, targetURL, domain string, words []string) []Result {
+	baselineHost := randomHex(16) + "." + domain
+	var baseline *ResponseSignature
+	if result, _, err := makeRequestWithHost(targetURL, baselineHost, getRandomUserAgent(), config); err == nil {
+		baseline = &ResponseSignature{StatusCode: result.StatusCode, Size: result.Size, WordCount: result.WordCount, LineCount: result.LineCount}
+	}
+
+	jobs := make(chan string, config.Threads*2)
+	discovered := make(chan string, config.Threads*2)
+
+	var workerWG sync.WaitGroup
+	for i := 0; i < config.Threads; i++ {
+		workerWG.Add(1)
+		go func() {
+			defer workerWG.Done()
+			for word := range jobs {
+				vhost := word + "." + domain
+				result, _, err := makeRequestWithHost(targetURL, vhost, getRandomUserAgent(), config)
+				if err != nil {
+					continue
+				}
+				if baseline != nil && result.StatusCode == baseline.StatusCode && result.Size == baseline.Size && result.WordCount == baseline.WordCount {
+					continue
+				}
+				discovered <- vhost
+			}
+		}()
+	}
+
+	go func() {
+		for _, word := range words {
+			jobs <- word
+		}
+		close(jobs)
+	}()
+	go func() {
+		workerWG.Wait()
+		close(discovered)
+	}()
+
+	var vhosts []string
+	for vhost := range discovered {
+		vhosts = append(vhosts, vhost)
+		fmt.Printf("%s[VHOST]%s %s\n", ColorNeonGreen, ColorReset, vhost)
+	}
+	sort.Strings(vhosts)
+
+	var results []Result
+	for _, vhost := range vhosts {
+		for _, word := range words {
+			pathURL := buildURL(targetURL, word)
+			result, body, err := makeRequestWithHost(pathURL, vhost, getRandomUserAgent(), config)
+			if err != nil || !isInteresting(result, config) {
+				continue
+			}
+			result.Word = word
+			result.Source = "vhost"
+			if shouldScanForSecrets(result.ContentType, len(body), config) {
+				if secrets := detectSecrets(body); len(secrets) > 0 {
+					result.SecretFound = true
+					result.SecretTypes = secrets
+					result.SecretClassification = classifySecretMaterial(body, secrets)
+				}
+			}
+			results = append(results, *result)
+		}
+	}
+	return results
+}
+
+// BRAIN 1: Smart Auto-Calibration
+// generateCalibrationProbe builds a randomized, unrecognizable probe shape
+// (random segment names, variable nesting depth, extension, method and
+// optional query string) so targets that fingerprint and special-case
+// scanner calibration paths can't special-case a fixed prefix
+func generateCalibrationProbe() (path, method string) {
+	extensions := []string{"", ".html", ".php", ".json", ".aspx"}
+	methods := []string{"GET", "GET", "GET", "HEAD"}
+	depth := 1 + rand.Intn(3)
+
+	segments := make([]string, depth)
+	for i := range segments {
+		segments[i] = randomHex(6 + rand.Intn(6))
+	}
+	path = "/" + strings.Join(segments, "/") + extensions[rand.Intn(len(extensions))]
+
+	if rand.Intn(2) == 0 {
+		path += fmt.Sprintf("?%s=%s", randomHex(4), randomHex(8))
+	}
+
+	method = methods[rand.Intn(len(methods))]
+	recordCanary("calibration-probe", path, "auto-calibration baseline probe ("+method+")")
+	return path, method
+}
+
+func randomHex(n int) string {
+	const charset = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = charset[rand.Intn(len(charset))]
+	}
+	return string(b)
+}
+
+// robotsRules holds the Disallow prefixes from a target's robots.txt that
+// apply to capsaicin, for -respect-robots polite self-assessment scans
+type robotsRules struct {
+	disallow []string
+}
+
+// blocks reports whether path falls under a Disallow prefix; a nil
+// *robotsRules (robots.txt missing, or -respect-robots not set) never blocks
+func (r *robotsRules) blocks(path string) bool {
+	if r == nil {
+		return false
+	}
+	for _, prefix := range r.disallow {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRobotsRules downloads and parses targetURL's robots.txt, collecting
+// Disallow entries under a "User-agent: *" or "User-agent: capsaicin" block
+func fetchRobotsRules(targetURL string, config Config) *robotsRules {
+	rules := &robotsRules{}
+
+	result, body, err := makeRequestWithUA(buildURL(targetURL, "/robots.txt"), "GET", getRandomUserAgent(), config)
+	if err != nil || result.StatusCode != http.StatusOK {
+		return rules
+	}
+
+	applies := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		directive := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch directive {
+		case "user-agent":
+			applies = value == "*" || strings.EqualFold(value, "capsaicin")
+		case "disallow":
+			if applies && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		}
+	}
+
+	return rules
+}
+
+// calibrationRefreshInterval is how long a cached calibration stays valid
+// before getCalibrationSignatures recalibrates on the next access
+const calibrationRefreshInterval = 5 * time.Minute
+
+type calibrationCacheEntry struct {
+	signatures []ResponseSignature
+	expiresAt  time.Time
+}
+
+// calibrationMu guards calibrationCache; every worker used to call
+// performCalibration for every target on startup, multiplying calibration
+// traffic by thread count. This shared, mutex-protected cache populates
+// once per target on first access and refreshes lazily once the entry's
+// TTL elapses, instead of on every worker's startup.
+var (
+	calibrationMu    sync.Mutex
+	calibrationCache = make(map[string]*calibrationCacheEntry)
+)
+
+func getCalibrationSignatures(target string, config Config) []ResponseSignature {
+	calibrationMu.Lock()
+	defer calibrationMu.Unlock()
+
+	if entry, ok := calibrationCache[target]; ok && time.Now().Before(entry.expiresAt) {
+		return entry.signatures
+	}
+
+	signatures := performCalibration(target, config)
+	calibrationCache[target] = &calibrationCacheEntry{
+		signatures: signatures,
+		expiresAt:  time.Now().Add(calibrationRefreshInterval),
+	}
+	return signatures
+}
+
+func performCalibration(targetURL string, config Config) []ResponseSignature {
+	signatures := make([]ResponseSignature, 0, 4)
+
+	fmt.Printf("%s[🧠 BRAIN-1]%s Calibrating: %s\n", ColorCyan+ColorBold, ColorReset, targetURL)
+
+	// -calibration-path lets the operator supply known-404 URLs directly,
+	// for apps where a random path gets rewritten to the SPA index instead
+	// of 404ing, but a specific missing asset path 404s correctly
+	if len(config.CalibrationPaths) > 0 {
+		for _, path := range config.CalibrationPaths {
+			url := buildURL(targetURL, path)
+			sig := fetchSignature(url, "GET", config)
+			if sig != nil {
+				signatures = append(signatures, *sig)
+			}
+		}
+		return signatures
+	}
+
+	// Multiple randomized baselines defend against targets that detect and
+	// special-case scanner calibration probes
+	for i := 0; i < 4; i++ {
+		path, method := generateCalibrationProbe()
+		url := buildURL(targetURL, path)
+		sig := fetchSignature(url, method, config)
+		if sig != nil {
+			signatures = append(signatures, *sig)
+		}
+	}
+
+	return signatures
+}
+
+func fetchSignature(url, method string, config Config) *ResponseSignature {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", getRandomUserAgent())
+
+	// Apply custom headers
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+	applyLoginToken(req)
+
+	resp, err := calibrationHTTPClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil
+	}
+
+	return &ResponseSignature{
+		StatusCode: resp.StatusCode,
+		Size:       len(body),
+		WordCount:  len(strings.Fields(string(body))),
+		LineCount:  strings.Count(string(body), "\n") + 1,
+		SimHash:    simhash64(body),
+	}
+}
+
+// simhash64 builds a 64-bit simhash of body by tokenizing it on whitespace,
+// FNV-hashing each token, and accumulating a bit vector weighted by each
+// hash's bit values - documents with mostly-overlapping tokens land on a
+// simhash with a small Hamming distance even if their exact byte size
+// differs (templated custom-404 pages with a varying timestamp or request ID)
+func simhash64(body []byte) uint64 {
+	tokens := strings.Fields(string(body))
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, token := range tokens {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		hash := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if hash&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var sig uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			sig |= 1 << uint(bit)
+		}
+	}
+	return sig
+}
+
+// soft404SimHashThreshold is the max Hamming distance (out of 64 bits)
+// between a result's content simhash and a calibration signature's for the
+// two bodies to be considered the same templated page
+const soft404SimHashThreshold = 3
+
+// loadWordlist accepts a plain text file, a gzip-compressed file (.gz), or a
+// directory (merges every *.txt inside), deduplicating entries across sources
+func loadWordlist(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var words []string
+
+	addFile := func(filePath string) error {
+		lines, err := readWordlistFile(filePath)
+		if err != nil {
+			return err
+		}
+		for _, word := range lines {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+		return nil
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+				continue
+			}
+			if err := addFile(path + "/" + entry.Name()); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if err := addFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return words, nil
+}
+
+// readWordlistFile reads a single wordlist file, transparently gunzipping it
+// if it has a .gz extension
+func readWordlistFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip wordlist %s: %w", path, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	var words []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" && !strings.HasPrefix(word, "#") {
+			words = append(words, word)
+		}
+	}
+
+	return words, scanner.Err()
+}
+
+// Worker function with all advanced features
+func worker(id int, config Config, tasks <-chan Task, results chan<- Result, newTasks chan<- Task, retryTasks chan<- Task,
+	timeoutTasks chan<- Task, stats *Stats, wg *sync.WaitGroup, scannedDirs *map[string]map[string]bool, dirMutex *sync.Mutex,
+	words []string) {
+	defer wg.Done()
+
+	consecutiveErrors := 0
+	maxConsecutiveErrors := 5
+
+	for task := range tasks {
+		if config.KeyboardControls {
+			for scanPaused.Load() {
+				time.Sleep(150 * time.Millisecond)
+			}
+			for int64(id) >= activeThreadCap.Load() {
+				time.Sleep(150 * time.Millisecond)
+			}
+		}
+
+		// Throttle-aware rebalancing: don't burn this worker's capacity on a
+		// target that's actively blocking/rate-limiting us. Hand the task to
+		// the retry queue so it's retried after the cooldown, and move on to
+		// whatever other target's task is next in the queue.
+		if until, ok := targetThrottledUntil.Load(task.TargetURL); ok {
+			if time.Now().Before(until.(time.Time)) {
+				select {
+				case retryTasks <- task:
+					continue
+				default:
+					// retry queue is full; fall through and process now rather than drop the task
+				}
+			} else {
+				targetThrottledUntil.Delete(task.TargetURL)
+			}
+		}
+
+		if globalRateLimiter != nil {
+			globalRateLimiter.wait()
+		}
+		if config.RateLimitPerTarget > 0 {
+			limiter, _ := targetRateLimiters.LoadOrStore(task.TargetURL, newTokenBucket(config.RateLimitPerTarget))
+			limiter.(*tokenBucket).wait()
+		}
+		if config.Jitter > 0 {
+			time.Sleep(time.Duration(rand.Int63n(int64(config.Jitter))))
+		}
+
+		if !task.IsBase {
+			if confirmed, ok := confirmed404Words.Load(word404Key(task.TargetURL, task.Word)); ok && confirmed.(bool) {
+				atomic.AddInt64(&stats.Processed, 1)
+				continue
+			}
+		}
+
+		url := buildURL(task.TargetURL, task.Path)
+		recordWordProgress(task.TargetURL, task.WordIndex)
+
+		if config.Verbose && verboseSampled(config) {
+			fmt.Printf("%s[→]%s Testing: %s %s(word %d/%d, %.1f%% through wordlist)%s\n",
+				ColorCyan, ColorReset, url, ColorWhite, task.WordIndex+1, len(words), wordProgressPercent(task.TargetURL, len(words)), ColorReset)
+		}
+
+		userAgent := getRandomUserAgent()
+		var result *Result
+		var bodyContent string
+		var err error
+		retryCount := 0
+		for attempt := 0; ; attempt++ {
+			if config.RequestTemplate != nil {
+				result, bodyContent, err = makeRequestFromTemplate(config.RequestTemplate, task.TargetURL, userAgent, task.Word, config)
+			} else if config.FollowRedirects {
+				requestBody := strings.ReplaceAll(config.RequestBody, "FUZZ", task.Word)
+				method := config.RequestMethod
+				if method == "" {
+					method = "GET"
+				}
+				result, bodyContent, err = followRedirectChain(url, method, userAgent, requestBody, config)
+			} else if config.RequestBody != "" {
+				requestBody := strings.ReplaceAll(config.RequestBody, "FUZZ", task.Word)
+				result, bodyContent, err = makeRequestWithBody(url, config.RequestMethod, userAgent, requestBody, config)
+			} else {
+				result, bodyContent, err = makeRequestWithUA(url, "GET", userAgent, config)
+			}
+
+			// Only network errors and plain 5xx (excluding 429/503, which get
+			// their own Retry-After-aware backoff below) count as transient
+			// and worth retrying; anything else is a final answer
+			transient := err != nil || (result.StatusCode >= 500 && result.StatusCode != 503)
+			if !transient || attempt >= config.Retries {
+				break
+			}
+			retryCount++
+			if config.RetryDelay > 0 {
+				time.Sleep(config.RetryDelay * time.Duration(attempt+1))
+			}
+		}
+		atomic.AddInt64(&stats.Processed, 1)
+
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() && timeoutTasks != nil {
+				select {
+				case timeoutTasks <- task:
+				default:
+					// timeout retry queue is full; fall back to counting it as a permanent error
+					atomic.AddInt64(&stats.Errors, 1)
+				}
+				continue
+			}
+
+			atomic.AddInt64(&stats.Errors, 1)
+			consecutiveErrors++
+
+			if consecutiveErrors >= maxConsecutiveErrors {
+				time.Sleep(2 * time.Second)
+				consecutiveErrors = 0
+			}
+			continue
+		}
+
+		consecutiveErrors = 0
+		result.Word = task.Word
+		result.Source = task.Source
+		result.RetryCount = retryCount
+		if retryCount > 0 && config.Verbose {
+			suffix := "ies"
+			if retryCount == 1 {
+				suffix = "y"
+			}
+			fmt.Printf("%s[↻]%s %s succeeded after %d retr%s\n", ColorYellow, ColorReset, result.URL, retryCount, suffix)
+		}
+
+		if config.HTTP3 && strings.Contains(result.AltSvc, "h3") && config.Verbose {
+			fmt.Printf("%s[h3]%s %s advertises HTTP/3 via Alt-Svc: %s\n", ColorCyan, ColorReset, result.URL, result.AltSvc)
+		}
+
+		if config.HarvestFile != "" && result.StatusCode == 200 && bodyContent != "" {
+			harvestFromBody(task.TargetURL, bodyContent)
+		}
+
+		// A target that's told us its quota is already exhausted via
+		// X-RateLimit-Remaining is worth backing off for pre-emptively,
+		// instead of waiting to actually draw a 429
+		if result.RateLimitRemaining == "0" {
+			retryAfter, _ := parseRetryAfter(result.RateLimitReset)
+			recordRateLimitBackoff(task.TargetURL, retryAfter)
+		}
+
+		if result.StatusCode == 429 || result.StatusCode == 503 {
+			atomic.AddInt64(&stats.Throttled, 1)
+			backoff := recordRateLimitBackoff(task.TargetURL, result.RetryAfter)
+			if config.Verbose {
+				fmt.Printf("%s[⏳]%s %s throttled (%d) -- backing off %s\n",
+					ColorYellow, ColorReset, task.TargetURL, result.StatusCode, backoff.Round(time.Second))
+			}
+			select {
+			case retryTasks <- task:
+			default:
+			}
+			continue
+		}
+		clearRateLimitStreak(task.TargetURL)
+
+		blocked := result.StatusCode == 403 && result.WAFDetected != ""
+		blocked = blocked || isUniformBanPage(task.TargetURL, result.StatusCode, result.simHash)
+		if recordTargetBlockOutcome(task.TargetURL, blocked) {
+			fmt.Printf("\n%s[🚫 BAN DETECTED]%s %s is returning a uniform %d block page -- pausing for %s, rotating proxy/User-Agent before resuming\n",
+				ColorRed+ColorBold, ColorReset, task.TargetURL, result.StatusCode, targetThrottleCooldown)
+		}
+
+		if activeLoginConfig != nil {
+			loginRedirect := looksLikeLoginRedirect(result.StatusCode, result.RedirectLocation)
+			if recordLoginRedirect(task.TargetURL, loginRedirect) {
+				fmt.Printf("\n%s[🔑 SESSION EXPIRED]%s %s is redirecting to login -- re-running -login-config\n",
+					ColorYellow+ColorBold, ColorReset, task.TargetURL)
+				if err := refreshLogin(config); err != nil {
+					fmt.Printf("%s[ERROR]%s Session refresh failed: %s\n", ColorRed+ColorBold, ColorReset, err)
+				}
+			}
+		}
+
+		effectiveSignatures := signaturesFor(task.TargetURL, task.Path, getCalibrationSignatures(task.TargetURL, config))
+
+		if matchesSignature(result, effectiveSignatures) {
+			if task.IsBase {
+				confirmed404Words.Store(word404Key(task.TargetURL, task.Word), true)
+			}
+			continue
+		}
+
+		// HTTP Method Fuzzing on 405 (aggressive module - requires canary confirmation)
+		if result.StatusCode == 405 && config.Aggressive {
+			logAggressiveUse(task.TargetURL, "method-fuzz", url)
+			alternativeMethods := []string{"POST", "PUT", "DELETE", "PATCH"}
+			headerDiffs := diffMethodSecurityHeaders(url, userAgent, config)
+			for _, method := range alternativeMethods {
+				if (method == "PUT" || method == "DELETE") && !config.AllowDestructive {
+					atomic.AddInt64(&stats.DestructiveSkipped, 1)
+					if config.Verbose {
+						fmt.Printf("%s[skip]%s %s %s on %s skipped (requires -allow-destructive)\n",
+							ColorYellow, ColorReset, method, "method-fuzz", url)
+					}
+					continue
+				}
+				methodResult, methodBody, err := makeRequestWithUA(url, method, userAgent, config)
+				if err == nil && (methodResult.StatusCode == 200 || methodResult.StatusCode == 201 || methodResult.StatusCode == 204) {
+					methodResult.Method = method
+					methodResult.Critical = true
+					methodResult.Word = task.Word
+					methodResult.Source = "method-fuzz"
+					methodResult.MethodHeaderDiffs = headerDiffs
+
+					if shouldScanForSecrets(methodResult.ContentType, len(methodBody), config) {
+						if secrets := detectSecrets(methodBody); len(secrets) > 0 {
+							methodResult.SecretFound = true
+							methodResult.SecretTypes = secrets
+							methodResult.SecretClassification = classifySecretMaterial(methodBody, secrets)
+							atomic.AddInt64(&stats.Secrets, 1)
+						}
+					}
+
+					atomic.AddInt64(&stats.Found, 1)
+					results <- *methodResult
+					break
+				}
+			}
+		}
+
+		if isInteresting(result, config) && matchesBodyFilters(bodyContent, config, result) {
+			atomic.AddInt64(&stats.Found, 1)
+
+			// Secret Detection
+			if result.StatusCode == 200 && shouldScanForSecrets(result.ContentType, len(bodyContent), config) {
+				secrets := detectSecrets(bodyContent)
+				if config.DeepSecrets {
+					for _, deepSecret := range scanDeepSecretAssets(url, bodyContent, userAgent, config) {
+						if !containsString(secrets, deepSecret) {
+							secrets = append(secrets, deepSecret)
+						}
+					}
+				}
+				if len(secrets) > 0 {
+					result.SecretFound = true
+					result.SecretTypes = secrets
+					result.SecretClassification = classifySecretMaterial(bodyContent, secrets)
+					atomic.AddInt64(&stats.Secrets, 1)
+
+					if config.ValidateSecrets {
+						if live := validateSecretMaterial(bodyContent, secrets); len(live) > 0 {
+							result.LiveSecrets = live
+							result.Critical = true
+						}
+					}
+				}
+			}
+
+			// PII Detection - a distinct finding class from credentials/secrets
+			if result.StatusCode == 200 && len(bodyContent) > 0 {
+				if pii := detectPII(bodyContent); len(pii) > 0 {
+					result.PIIFound = true
+					result.PIITypes = pii
+					atomic.AddInt64(&stats.PII, 1)
+				}
+			}
+
+			// Internal infrastructure leakage - a distinct finding class from
+			// credentials/PII, since IPs/hostnames/UNC paths aren't sensitive
+			// on their own, only as recon fingerprints
+			if result.StatusCode == 200 && len(bodyContent) > 0 {
+				if disclosures := detectInfoDisclosure(bodyContent); len(disclosures) > 0 {
+					result.InfoDisclosure = disclosures
+					atomic.AddInt64(&stats.InfoDisclosure, 1)
+				}
+			}
+
+			// Response-body keyword tagging
+			if len(config.TagRules) > 0 && len(bodyContent) > 0 {
+				result.Tags = applyTags(bodyContent, config.TagRules)
+			}
+
+			// HEAD vs GET discrepancy - access control or caching applied
+			// inconsistently per method often shows up as a status or length
+			// mismatch between the two
+			if config.HeadDiffCheck && result.Method == "GET" {
+				result.HeadGetDiscrepancy = checkHeadGetDiscrepancy(url, userAgent, result, config)
+			}
+
+			// Safe canary upload probe on detected upload forms (aggressive module)
+			if result.Category == "upload-form" && config.Aggressive && config.ProbeUploads {
+				logAggressiveUse(task.TargetURL, "upload-probe", url)
+				if uploadResult, _ := attemptCanaryUpload(task.TargetURL, task.Path, userAgent, config); uploadResult != nil {
+					uploadResult.Word = task.Word
+					uploadResult.Source = "upload-probe"
+					atomic.AddInt64(&stats.Found, 1)
+					results <- *uploadResult
+				}
+			}
+
+			// WAF-blocked path obfuscation bypass (aggressive module)
+			if result.StatusCode == 403 && result.WAFDetected != "" && config.Aggressive {
+				logAggressiveUse(task.TargetURL, "obfuscation-bypass", url)
+				if obfResult, obfBody := attemptObfuscationBypass(task.TargetURL, task.Path, userAgent, config, effectiveSignatures); obfResult != nil {
+					obfResult.Word = task.Word
+					obfResult.Source = "obfuscation-bypass"
+					obfResult.ParentURL = url
+					if shouldScanForSecrets(obfResult.ContentType, len(obfBody), config) {
+						if secrets := detectSecrets(obfBody); len(secrets) > 0 {
+							obfResult.SecretFound = true
+							obfResult.SecretTypes = secrets
+							obfResult.SecretClassification = classifySecretMaterial(obfBody, secrets)
+							atomic.AddInt64(&stats.Secrets, 1)
+						}
+					}
+					results <- *obfResult
+				}
+			}
+
+			// Legacy protocol downgrade retry on blocked/rejected paths (aggressive module)
+			if (result.StatusCode == 400 || result.StatusCode == 403) && config.Aggressive && config.ProtocolDowngrade {
+				logAggressiveUse(task.TargetURL, "protocol-downgrade", url)
+				if downgradeResult, downgradeBody, err := attemptProtocolDowngrade(url, userAgent, config); err == nil && isInteresting(downgradeResult, config) && !matchesSignature(downgradeResult, effectiveSignatures) {
+					downgradeResult.Word = task.Word
+					downgradeResult.Source = "protocol-downgrade"
+					downgradeResult.ParentURL = url
+					if shouldScanForSecrets(downgradeResult.ContentType, len(downgradeBody), config) {
+						if secrets := detectSecrets(downgradeBody); len(secrets) > 0 {
+							downgradeResult.SecretFound = true
+							downgradeResult.SecretTypes = secrets
+							downgradeResult.SecretClassification = classifySecretMaterial(downgradeBody, secrets)
+							atomic.AddInt64(&stats.Secrets, 1)
+						}
+					}
+					atomic.AddInt64(&stats.Found, 1)
+					results <- *downgradeResult
+				}
+			}
+
+			// Active 403/401 Bypass (aggressive module)
+			if (result.StatusCode == 403 || result.StatusCode == 401) && config.Aggressive {
+				logAggressiveUse(task.TargetURL, "header-bypass+mutation", url)
+				bypassResult, bypassBody := attemptBypass(url, userAgent, config)
+				if bypassResult != nil && (bypassResult.StatusCode == 200 || bypassResult.StatusCode == 302) {
+					bypassResult.Critical = true
+					bypassResult.Word = task.Word
+					bypassResult.Source = "bypass"
+
+					if shouldScanForSecrets(bypassResult.ContentType, len(bypassBody), config) {
+						if secrets := detectSecrets(bypassBody); len(secrets) > 0 {
+							bypassResult.SecretFound = true
+							bypassResult.SecretTypes = secrets
+							bypassResult.SecretClassification = classifySecretMaterial(bypassBody, secrets)
+							atomic.AddInt64(&stats.Secrets, 1)
+						}
+					}
+
+					results <- *bypassResult
+
+					mutations := generateMutations(task.Path)
+					for technique, mutation := range mutations {
+						mutatedURL := buildURL(task.TargetURL, mutation)
+						mutatedResult, mutatedBody, err := makeRequestWithUA(mutatedURL, "GET", userAgent, config)
+						if err == nil && isInteresting(mutatedResult, config) && !matchesSignature(mutatedResult, effectiveSignatures) {
+							mutatedResult.Word = task.Word
+							mutatedResult.Source = "mutation"
+							mutatedResult.ParentURL = url
+							mutatedResult.MutationTechnique = technique
+							if mutatedResult.StatusCode != bypassResult.StatusCode {
+								mutatedResult.BehaviorDiff = fmt.Sprintf("status %d vs parent %d", mutatedResult.StatusCode, bypassResult.StatusCode)
+							} else if mutatedResult.Size != bypassResult.Size {
+								mutatedResult.BehaviorDiff = fmt.Sprintf("size %d vs parent %d", mutatedResult.Size, bypassResult.Size)
+							}
+							if shouldScanForSecrets(mutatedResult.ContentType, len(mutatedBody), config) {
+								if secrets := detectSecrets(mutatedBody); len(secrets) > 0 {
+									mutatedResult.SecretFound = true
+									mutatedResult.SecretTypes = secrets
+									mutatedResult.SecretClassification = classifySecretMaterial(mutatedBody, secrets)
+									atomic.AddInt64(&stats.Secrets, 1)
+								}
+							}
+							results <- *mutatedResult
+						}
+					}
+				}
+			}
+
+			// Recursive Discovery
+			if config.MaxDepth > 0 && task.Depth < config.MaxDepth {
+				if isDirectory(result) {
+					dirPath := extractPath(url)
+					if isRedirectStatus(result.StatusCode) {
+						if seededPath, ok := redirectRecursionSeed(url, result.RedirectLocation); ok && seededPath != dirPath {
+							result.RedirectSeededPath = seededPath
+							dirPath = seededPath
+						}
+					}
+					if config.Verbose && verboseSampled(config) {
+						fmt.Printf("%s[RECURSE]%s Found directory: %s (Depth: %d)\n",
+							ColorYellow, ColorReset, dirPath, task.Depth)
+					}
+					newTasks <- Task{
+						TargetURL: task.TargetURL,
+						Path:      dirPath,
+						Depth:     task.Depth + 1,
+						WordIndex: task.WordIndex,
+						Word:      task.Word,
+						Source:    "recursion",
+					}
+				}
+			}
+
+			if config.EvidenceDir != "" && (result.Critical || result.SecretFound) {
+				if err := captureEvidence(config.EvidenceDir, config.EvidenceKeyFile, result.URL, bodyContent); err != nil {
+					fmt.Printf("%s[WARN]%s Failed to capture evidence for %s: %s\n", ColorYellow, ColorReset, result.URL, err)
+				}
+			}
+
+			results <- *result
+		}
+	}
+}
+
+// requestTrace captures httptrace timing phases for a single traced request
+type requestTrace struct {
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotFirstByte time.Time
+}
+
+func newRequestTrace() *requestTrace {
+	return &requestTrace{start: time.Now()}
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { t.connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { t.connectDone = time.Now() },
+		TLSHandshakeStart:    func() { t.tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.tlsDone = time.Now() },
+		GotFirstResponseByte: func() { t.gotFirstByte = time.Now() },
+	}
+}
+
+// dump prints the full request/response along with timing phases to stdout
+func (t *requestTrace) dump(req *http.Request, resp *http.Response, reqErr error) {
+	fmt.Printf("\n%s[TRACE]%s %s %s\n", ColorMagenta+ColorBold, ColorReset, req.Method, req.URL.String())
+	fmt.Printf("%s[TRACE] Request Headers:%s\n", ColorMagenta, ColorReset)
+	for key, values := range req.Header {
+		fmt.Printf("  %s: %s\n", key, strings.Join(values, ", "))
+	}
+
+	if !t.dnsStart.IsZero() {
+		fmt.Printf("%s[TRACE] DNS:%s %s\n", ColorMagenta, ColorReset, t.dnsDone.Sub(t.dnsStart))
+	}
+	if !t.connectStart.IsZero() {
+		fmt.Printf("%s[TRACE] Connect:%s %s\n", ColorMagenta, ColorReset, t.connectDone.Sub(t.connectStart))
+	}
+	if !t.tlsStart.IsZero() {
+		fmt.Printf("%s[TRACE] TLS Handshake:%s %s\n", ColorMagenta, ColorReset, t.tlsDone.Sub(t.tlsStart))
+	}
+	if !t.gotFirstByte.IsZero() {
+		fmt.Printf("%s[TRACE] Time to First Byte:%s %s\n", ColorMagenta, ColorReset, t.gotFirstByte.Sub(t.start))
+	}
+
+	if reqErr != nil {
+		fmt.Printf("%s[TRACE] Error:%s %s\n\n", ColorRed, ColorReset, reqErr)
+		return
+	}
+
+	fmt.Printf("%s[TRACE] Response:%s %s\n", ColorMagenta, ColorReset, resp.Status)
+	fmt.Printf("%s[TRACE] Response Headers:%s\n", ColorMagenta, ColorReset)
+	for key, values := range resp.Header {
+		fmt.Printf("  %s: %s\n", key, strings.Join(values, ", "))
+	}
+	fmt.Println()
+}
+
+func makeRequestWithUA(url, method, userAgent string, config Config) (*Result, string, error) {
+	return makeRequestWithBody(url, method, userAgent, "", config)
+}
+
+// makeRequestWithBody is makeRequestWithUA plus an optional request body, for
+// -d/-data/-data-file body fuzzing against POST/PUT-style API endpoints
+func makeRequestWithBody(url, method, userAgent, requestBody string, config Config) (*Result, string, error) {
+	var bodyReader io.Reader
+	if requestBody != "" {
+		bodyReader = strings.NewReader(requestBody)
+	}
+	req, err := http.NewRequest(method, url, bodyReader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	if requestBody != "" && config.ContentType != "" {
+		req.Header.Set("Content-Type", config.ContentType)
+	}
+
+	// NEW FEATURE: Apply custom headers to ALL requests
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+	applyLoginToken(req)
+
+	// NEW FEATURE: Structured per-request tracing for debugging
+	var trace *requestTrace
+	if config.TraceURLPattern != nil && config.TraceURLPattern.MatchString(url) {
+		trace = newRequestTrace()
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+	}
+
+	requestStart := time.Now()
+	resp, err := httpClient.Do(req)
+	if trace != nil {
+		trace.dump(req, resp, err)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	responseTime := time.Since(requestStart)
+
+	bodyContent := string(body)
+	server := resp.Header.Get("Server")
+	poweredBy := resp.Header.Get("X-Powered-By")
+	tlsVersion, tlsCipher := tlsConnectionInfo(resp)
+	rlLimit, rlRemaining, rlReset := rateLimitHeaders(resp.Header)
+
+	result := &Result{
+		URL:                url,
+		StatusCode:         resp.StatusCode,
+		Size:               len(body),
+		WordCount:          len(strings.Fields(bodyContent)),
+		LineCount:          strings.Count(bodyContent, "\n") + 1,
+		Method:             method,
+		Timestamp:          time.Now().Format(time.RFC3339),
+		Server:             server,
+		PoweredBy:          poweredBy,
+		UserAgent:          userAgent,
+		Protocol:           resp.Proto,
+		AltSvc:             resp.Header.Get("Alt-Svc"),
+		TLSVersion:         tlsVersion,
+		TLSCipher:          tlsCipher,
+		RateLimitLimit:     rlLimit,
+		RateLimitRemaining: rlRemaining,
+		RateLimitReset:     rlReset,
+		ContentHash:        sha256Hex(body),
+		simHash:            simhash64(body),
+		ResponseTimeMs:     responseTime.Milliseconds(),
+	}
+
+	if isRedirectStatus(resp.StatusCode) {
+		result.RedirectLocation = resp.Header.Get("Location")
+	}
+
+	if resp.StatusCode == 429 || resp.StatusCode == 503 {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			result.RetryAfter = retryAfter
+		}
+	}
+
+	if len(config.CaptureHeaders) > 0 {
+		result.Headers = captureResponseHeaders(resp.Header, config.CaptureHeaders)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		result.ContentType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+
+	// Content-Length vs actual body mismatch (middleware interference, truncated chunked responses)
+	if resp.ContentLength >= 0 {
+		result.ContentLengthHeader = int(resp.ContentLength)
+		if int(resp.ContentLength) != len(body) {
+			result.LengthMismatch = true
+		}
+	}
+
+	// NEW FEATURE: WAF Detection -- skipped under -local, where there's no
+	// adversarial WAF to detect and the extra per-response analysis is pure
+	// overhead
+	if !config.LocalMode {
+		if wafName := detectWAF(resp, bodyContent); wafName != "" {
+			result.WAFDetected = wafName
+		}
+	}
+
+	if issues := auditSetCookie(resp); len(issues) > 0 {
+		result.CookieIssues = issues
+	}
+
+	if anomalies := auditHeaderAnomalies(resp.Header); len(anomalies) > 0 {
+		result.HeaderAnomalies = anomalies
+	}
+
+	if csp := resp.Header.Get("Content-Security-Policy"); csp != "" {
+		result.CSPIssues, result.CSPThirdPartyOrigins = auditCSP(csp)
+	}
+
+	result.Category = classifyPage(extractPath(url), resp.Header.Get("Content-Type"), bodyContent)
+	result.SchemaSample = sampleSchema(resp.Header.Get("Content-Type"), bodyContent)
+
+	// Surface the authentication mechanism guarding a 401, so results can be
+	// filtered by scheme (e.g. "show me all NTLM endpoints") and the auth
+	// module knows which mechanism to attempt
+	if resp.StatusCode == http.StatusUnauthorized {
+		result.AuthScheme, result.AuthRealm = parseWWWAuthenticate(resp.Header.Get("WWW-Authenticate"))
+	}
+
+	result.CurlCommand = generateCurlCommand(url, method, userAgent, config)
+
+	return result, bodyContent, nil
+}
+
+// captureResponseHeaders pulls the headers named in want (case-insensitive)
+// out of a response, or every header present if want is exactly ["*"]
+func captureResponseHeaders(header http.Header, want []string) map[string]string {
+	if len(want) == 1 && want[0] == "*" {
+		captured := make(map[string]string, len(header))
+		for name := range header {
+			captured[name] = header.Get(name)
+		}
+		return captured
+	}
+
+	captured := make(map[string]string)
+	for _, name := range want {
+		if value := header.Get(name); value != "" {
+			captured[name] = value
+		}
+	}
+	if len(captured) == 0 {
+		return nil
+	}
+	return captured
+}
+
+func isRedirectStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// followRedirectChain is makeRequestWithBody plus manual redirect following,
+// for -follow-redirects. httpClient is otherwise configured never to follow
+// redirects on its own (CheckRedirect returns http.ErrUseLastResponse) so
+// that every other code path sees the redirect response itself; this walks
+// the chain one hop at a time instead, recording each "status url" visited
+// and stopping at config.MaxRedirects hops or as soon as a hop's host falls
+// outside the original target's scope.
+func followRedirectChain(url, method, userAgent, requestBody string, config Config) (*Result, string, error) {
+	originalHost := extractHost(url)
+	currentURL := url
+
+	var result *Result
+	var bodyContent string
+	var err error
+	var chain []string
+
+	for hop := 0; ; hop++ {
+		result, bodyContent, err = makeRequestWithBody(currentURL, method, userAgent, requestBody, config)
+		if err != nil {
+			return nil, "", err
+		}
+		chain = append(chain, fmt.Sprintf("%d %s", result.StatusCode, currentURL))
+
+		if !isRedirectStatus(result.StatusCode) || result.RedirectLocation == "" {
+			break
+		}
+
+		nextURL, err := resolveRedirectURL(currentURL, result.RedirectLocation)
+		if err != nil {
+			break
+		}
+		if extractHost(nextURL) != originalHost {
+			result.OutOfScopeRedirect = true
+			break
+		}
+		if hop >= config.MaxRedirects {
+			break
+		}
+		currentURL = nextURL
+	}
+
+	result.RedirectChain = chain
+	result.FinalURL = currentURL
+	result.FinalStatusCode = result.StatusCode
+	return result, bodyContent, nil
+}
+
+// resolveRedirectURL resolves a Location header (absolute or relative)
+// against the URL that produced it
+func resolveRedirectURL(base, location string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	locURL, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(locURL).String(), nil
+}
+
+// extractHost returns the host:port portion of a URL, or "" if it doesn't parse
+func extractHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// methodDiffHeaders lists response headers whose presence or absence varies
+// meaningfully by HTTP method - a CORS header that only shows up on OPTIONS,
+// or an auth challenge that only shows up on GET, usually means a piece of
+// middleware only covers some verbs
+var methodDiffHeaders = []string{
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Methods",
+	"Www-Authenticate",
+	"X-Frame-Options",
+	"Content-Security-Policy",
+	"Set-Cookie",
+}
+
+// diffMethodSecurityHeaders issues GET, OPTIONS, and POST requests against
+// the same URL and reports security-relevant headers that are present on
+// some methods but not others, e.g. CORS headers only on OPTIONS or auth
+// enforced only on GET, surfacing inconsistent method coverage as a finding
+// instead of letting it hide in raw per-request output
+func diffMethodSecurityHeaders(targetURL, userAgent string, config Config) []string {
+	methods := []string{"GET", "OPTIONS", "POST"}
+	seenOn := make(map[string][]string)
+	statusByMethod := make(map[string]int)
+
+	for _, method := range methods {
+		req, err := http.NewRequest(method, targetURL, nil)
+		if err != nil {
+			continue
+		}
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			continue
+		}
+		statusByMethod[method] = resp.StatusCode
+		for _, header := range methodDiffHeaders {
+			if resp.Header.Get(header) != "" {
+				seenOn[header] = append(seenOn[header], method)
+			}
+		}
+		resp.Body.Close()
+	}
+
+	var diffs []string
+	for _, header := range methodDiffHeaders {
+		methodsWithHeader := seenOn[header]
+		if len(methodsWithHeader) == 0 || len(methodsWithHeader) == len(methods) {
+			continue
+		}
+		var missing []string
+		for _, method := range methods {
+			if _, ok := statusByMethod[method]; !ok {
+				continue
+			}
+			has := false
+			for _, m := range methodsWithHeader {
+				if m == method {
+					has = true
+					break
+				}
+			}
+			if !has {
+				missing = append(missing, method)
+			}
+		}
+		diffs = append(diffs, fmt.Sprintf("%s present on %s but missing on %s", header, strings.Join(methodsWithHeader, "/"), strings.Join(missing, "/")))
+	}
+
+	if statusByMethod["GET"] == http.StatusOK && isAuthEnforced(statusByMethod["OPTIONS"]) {
+		diffs = append(diffs, "auth enforced on OPTIONS but not on GET")
+	}
+	if statusByMethod["OPTIONS"] == http.StatusOK && isAuthEnforced(statusByMethod["GET"]) {
+		diffs = append(diffs, "auth enforced on GET but not on OPTIONS")
+	}
+
+	return diffs
+}
+
+func isAuthEnforced(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// checkHeadGetDiscrepancy issues a HEAD request against targetURL and
+// compares it to the already-fetched GET result, flagging a status code
+// mismatch or a drastically different length (more than 50% apart) --
+// often a sign that access control or caching middleware only covers one
+// of the two methods
+func checkHeadGetDiscrepancy(targetURL, userAgent string, getResult *Result, config Config) string {
+	req, err := http.NewRequest("HEAD", targetURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != getResult.StatusCode {
+		return fmt.Sprintf("HEAD status %d != GET status %d", resp.StatusCode, getResult.StatusCode)
+	}
+
+	if resp.ContentLength >= 0 && getResult.Size > 0 {
+		larger, smaller := float64(resp.ContentLength), float64(getResult.Size)
+		if larger < smaller {
+			larger, smaller = smaller, larger
+		}
+		if (larger-smaller)/larger > 0.5 {
+			return fmt.Sprintf("HEAD Content-Length=%d vs GET body size=%d", resp.ContentLength, getResult.Size)
+		}
+	}
+
+	return ""
+}
+
+// cspImportantDirectives are the directives whose absence meaningfully
+// weakens a CSP (missing default-src alone is tolerable if these are set)
+var cspImportantDirectives = []string{"script-src", "object-src", "base-uri", "frame-ancestors"}
+
+// auditCSP parses a Content-Security-Policy header, flags unsafe-inline,
+// unsafe-eval and wildcard sources plus missing important directives, and
+// lists the third-party script/style origins the policy allows
+func auditCSP(header string) (issues []string, thirdPartyOrigins []string) {
+	directives := make(map[string][]string)
+	for _, part := range strings.Split(header, ";") {
+		fields := strings.Fields(strings.TrimSpace(part))
+		if len(fields) == 0 {
+			continue
+		}
+		directives[strings.ToLower(fields[0])] = fields[1:]
+	}
+
+	if _, ok := directives["default-src"]; !ok {
+		for _, d := range cspImportantDirectives {
+			if _, ok := directives[d]; !ok {
+				issues = append(issues, "missing directive: "+d)
+			}
+		}
+	}
+
+	seenOrigin := make(map[string]bool)
+	for directive, sources := range directives {
+		if directive != "script-src" && directive != "style-src" && directive != "default-src" {
+			continue
+		}
+		for _, src := range sources {
+			switch src {
+			case "'unsafe-inline'":
+				issues = append(issues, directive+": allows 'unsafe-inline'")
+			case "'unsafe-eval'":
+				issues = append(issues, directive+": allows 'unsafe-eval'")
+			case "*":
+				issues = append(issues, directive+": wildcard '*' source")
+			default:
+				if strings.Contains(src, "://") && !seenOrigin[src] {
+					seenOrigin[src] = true
+					thirdPartyOrigins = append(thirdPartyOrigins, src)
+				}
+			}
+		}
+	}
+
+	return issues, thirdPartyOrigins
+}
+
+var staticAssetExtensions = []string{
+	".css", ".js", ".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico",
+	".woff", ".woff2", ".ttf", ".eot", ".map", ".webp",
+}
+
+// classifyPage heuristically categorizes a finding from its path, response
+// body and Content-Type, so operators can facet results (login, admin,
+// upload form, API endpoint, documentation, static asset) instead of
+// manually opening every single discovered URL
+func classifyPage(path, contentType, body string) string {
+	lowerPath := strings.ToLower(path)
+	lowerBody := strings.ToLower(body)
+
+	for _, ext := range staticAssetExtensions {
+		if strings.HasSuffix(lowerPath, ext) {
+			return "static-asset"
+		}
+	}
+
+	switch {
+	case strings.Contains(lowerBody, `type="file"`) || strings.Contains(lowerBody, "multipart/form-data"):
+		return "upload-form"
+	case strings.Contains(lowerBody, `type="password"`) &&
+		(strings.Contains(lowerBody, "confirm password") || strings.Contains(lowerBody, "confirm_password") || strings.Contains(lowerPath, "signup") || strings.Contains(lowerPath, "register")):
+		return "signup"
+	case strings.Contains(lowerBody, `type="password"`) || strings.Contains(lowerPath, "login") || strings.Contains(lowerPath, "signin"):
+		return "login"
+	case strings.Contains(lowerPath, "admin") || strings.Contains(lowerPath, "dashboard") || strings.Contains(lowerPath, "panel") || strings.Contains(lowerPath, "cpanel"):
+		return "admin"
+	case strings.Contains(lowerPath, "swagger") || strings.Contains(lowerPath, "api-docs") || strings.Contains(lowerPath, "redoc") || strings.Contains(lowerPath, "readme"):
+		return "documentation"
+	case strings.Contains(contentType, "application/json") || strings.Contains(lowerPath, "/api/"):
+		return "api-endpoint"
+	default:
+		return ""
+	}
+}
+
+const schemaSampleMaxLen = 500
+
+// sampleSchema captures a truncated, key-only structural sample of an
+// API-ish response (JSON or XML) so analysts can see the shape of what an
+// endpoint returns without the scanner storing any actual payload values
+func sampleSchema(contentType, body string) string {
+	ct := strings.ToLower(contentType)
+	switch {
+	case strings.Contains(ct, "json"):
+		return sampleJSONSchema(body)
+	case strings.Contains(ct, "xml"):
+		return sampleXMLSchema(body)
+	default:
+		return ""
+	}
+}
+
+func sampleJSONSchema(body string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return ""
+	}
+	out, err := json.Marshal(jsonShape(v, 0))
+	if err != nil {
+		return ""
+	}
+	sample := string(out)
+	if len(sample) > schemaSampleMaxLen {
+		sample = sample[:schemaSampleMaxLen] + "...(truncated)"
+	}
+	return sample
+}
+
+// jsonShape replaces every scalar value in a decoded JSON document with its
+// type name and collapses arrays to a single representative element, so the
+// resulting structure shows field names and types but never real data
+func jsonShape(v interface{}, depth int) interface{} {
+	if depth > 4 {
+		return "..."
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		shape := make(map[string]interface{}, len(keys))
+		for _, k := range keys {
+			shape[k] = jsonShape(val[k], depth+1)
+		}
+		return shape
+	case []interface{}:
+		if len(val) == 0 {
+			return []interface{}{}
+		}
+		return []interface{}{jsonShape(val[0], depth+1)}
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+const schemaSampleMaxElements = 60
+
+// sampleXMLSchema walks an XML document's element tree and renders just the
+// tag names (indented by nesting depth), omitting all attribute and text values
+func sampleXMLSchema(body string) string {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	var sb strings.Builder
+	depth := 0
+	elements := 0
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			sb.WriteString(strings.Repeat("  ", depth) + "<" + t.Name.Local + ">\n")
+			depth++
+			elements++
+			if elements >= schemaSampleMaxElements {
+				sb.WriteString("...(truncated)\n")
+				return sb.String()
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return sb.String()
+}
+
+// debugHeaderPrefixes are response header names (lowercased) known to leak
+// internal infrastructure details when present; matched as a prefix so
+// vendor-specific suffixes (X-Debug-Token-Link, X-Internal-Host, ...) are
+// still caught
+var debugHeaderPrefixes = []string{
+	"x-debug", "x-backend-server", "x-served-by", "x-runtime",
+	"x-internal", "x-upstream", "x-aspnet-version", "x-aspnetmvc-version",
+	"x-generator", "x-varnish", "x-cache-server",
+}
+
+var privateIPPattern = regexp.MustCompile(`\b(?:10\.\d{1,3}\.\d{1,3}\.\d{1,3}|172\.(?:1[6-9]|2\d|3[01])\.\d{1,3}\.\d{1,3}|192\.168\.\d{1,3}\.\d{1,3})\b`)
+
+// auditHeaderAnomalies flags response headers that leak internal
+// infrastructure details: known debug/internal header names, and any header
+// value containing an RFC1918 private IP address. Returned unsorted order
+// isn't guaranteed stable since Go randomizes map iteration, so callers that
+// need deterministic output should sort.
+func auditHeaderAnomalies(header http.Header) []string {
+	var anomalies []string
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		for _, known := range debugHeaderPrefixes {
+			if strings.HasPrefix(lower, known) {
+				anomalies = append(anomalies, fmt.Sprintf("%s: %s", name, strings.Join(values, ", ")))
+				break
+			}
+		}
+		for _, v := range values {
+			if ip := privateIPPattern.FindString(v); ip != "" {
+				anomalies = append(anomalies, fmt.Sprintf("%s: internal IP %s", name, ip))
+			}
+		}
+	}
+	sort.Strings(anomalies)
+	return anomalies
+}
+
+// auditSetCookie inspects a response's Set-Cookie headers for missing
+// Secure/HttpOnly/SameSite flags and excessively long-lived session cookies,
+// returning a human-readable issue string per cookie that has a problem
+func auditSetCookie(resp *http.Response) []string {
+	var issues []string
+	for _, c := range resp.Cookies() {
+		var missing []string
+		if !c.Secure {
+			missing = append(missing, "Secure")
+		}
+		if !c.HttpOnly {
+			missing = append(missing, "HttpOnly")
+		}
+		if c.SameSite == http.SameSiteDefaultMode {
+			missing = append(missing, "SameSite")
+		}
+		if len(missing) > 0 {
+			issues = append(issues, fmt.Sprintf("%s: missing %s", c.Name, strings.Join(missing, "/")))
+		}
+
+		if looksLikeSessionCookie(c.Name) {
+			if lifetime := cookieLifetime(c); lifetime > 30*24*time.Hour {
+				issues = append(issues, fmt.Sprintf("%s: long-lived session cookie (%s)", c.Name, lifetime.Round(time.Hour)))
+			}
+		}
+	}
+	return issues
+}
+
+// looksLikeSessionCookie heuristically flags cookies likely to carry an
+// authenticated session, for the long-lived-session check in auditSetCookie
+func looksLikeSessionCookie(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range []string{"sess", "token", "auth", "sid", "jwt"} {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// cookieLifetime returns how long a cookie persists from Max-Age or Expires,
+// or zero if the cookie is a session-only cookie with neither set
+func cookieLifetime(c *http.Cookie) time.Duration {
+	if c.MaxAge > 0 {
+		return time.Duration(c.MaxAge) * time.Second
+	}
+	if !c.Expires.IsZero() {
+		return time.Until(c.Expires)
+	}
+	return 0
+}
+
+var wwwAuthRealmPattern = regexp.MustCompile(`(?i)realm\s*=\s*"([^"]*)"`)
+
+// parseWWWAuthenticate extracts the auth scheme (Basic, Bearer, Negotiate,
+// NTLM, ...) and realm from a WWW-Authenticate header value
+func parseWWWAuthenticate(header string) (scheme, realm string) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return "", ""
+	}
+	if space := strings.IndexAny(header, " \t"); space != -1 {
+		scheme = header[:space]
+	} else {
+		scheme = header
+	}
+	if m := wwwAuthRealmPattern.FindStringSubmatch(header); m != nil {
+		realm = m[1]
+	}
+	return scheme, realm
+}
+
+// NEW FEATURE: WAF Detection Engine
+func detectWAF(resp *http.Response, body string) string {
+	for _, waf := range wafSignatures {
+		// Check Server header
+		if waf.ServerHeader != "" {
+			if server := resp.Header.Get("Server"); strings.Contains(strings.ToLower(server), strings.ToLower(waf.ServerHeader)) {
+				return waf.Name
+			}
+		}
+
+		// Check custom headers
+		if waf.CustomHeader != "" {
+			for header := range resp.Header {
+				if strings.Contains(strings.ToLower(header), strings.ToLower(waf.CustomHeader)) {
+					return waf.Name
+				}
+			}
+		}
+
+		// Check cookies
+		if waf.CookiePattern != "" {
+			for _, cookie := range resp.Cookies() {
+				if strings.Contains(cookie.Name, waf.CookiePattern) {
+					return waf.Name
+				}
+			}
+		}
+
+		// Check status code, from -waf-signatures entries only
+		if waf.StatusCode != 0 && waf.StatusCode != resp.StatusCode {
+			continue
+		}
+
+		// Check body regex, from -waf-signatures entries only
+		if waf.bodyRegexCompiled != nil && waf.bodyRegexCompiled.MatchString(body) {
+			return waf.Name
+		}
+		if waf.StatusCode != 0 && waf.bodyRegexCompiled == nil {
+			return waf.Name
+		}
+	}
+
+	return ""
+}
+
+// loadWAFSignatures reads a JSON array of WAFSignature from path, compiling
+// each entry's BodyRegex, and appends it to the built-in wafSignatures so
+// users can fingerprint new WAF/CDN products without recompiling
+func loadWAFSignatures(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var extra []WAFSignature
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	for i := range extra {
+		if extra[i].BodyRegex != "" {
+			re, err := regexp.Compile(extra[i].BodyRegex)
+			if err != nil {
+				return fmt.Errorf("signature %q: invalid BodyRegex: %w", extra[i].Name, err)
+			}
+			extra[i].bodyRegexCompiled = re
+		}
+	}
+	wafSignatures = append(wafSignatures, extra...)
+	return nil
+}
+
+func generateCurlCommand(url, method, userAgent string, config Config) string {
+	cmd := fmt.Sprintf(`curl -X %s "%s" -H "User-Agent: %s"`, method, url, userAgent)
+	for key, value := range config.CustomHeaders {
+		cmd += fmt.Sprintf(` -H "%s: %s"`, key, value)
+	}
+	return cmd
+}
+
+// NEW FEATURE: Response-body keyword tagging
+func applyTags(content string, rules []TagRule) []string {
+	var tags []string
+	for _, rule := range rules {
+		if strings.Contains(content, rule.Contains) {
+			tags = append(tags, rule.Name)
+		}
+	}
+	return tags
+}
+
+// defaultSecretScanMaxBytes caps full secret-regex scanning to responses
+// under ~2MB by default, so a handful of large binary/video assets in a
+// wordlist run don't dominate scan time running every credential pattern
+// against them
+const defaultSecretScanMaxBytes = 2 * 1024 * 1024
+
+// isTextualContentType reports whether contentType is worth running secret
+// detection against -- text/*, plus the handful of non-"text/" MIME types
+// that still commonly carry plaintext credentials (JSON, JS, XML). A
+// missing Content-Type header is treated as textual, giving servers that
+// omit it the benefit of the doubt rather than silently skipping them.
+func isTextualContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	ct := strings.ToLower(contentType)
+	if strings.HasPrefix(ct, "text/") {
+		return true
+	}
+	for _, textual := range []string{"application/json", "application/javascript", "application/xml", "application/xhtml+xml", "application/x-www-form-urlencoded"} {
+		if strings.HasPrefix(ct, textual) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldScanForSecrets gates full secret-pattern scanning to textual
+// content types under -secret-scan-max-bytes -- previously every 200 with
+// a non-empty body ran the full regex set regardless of type or size,
+// HTML-only-by-accident since that's almost all a typical wordlist turns up
+func shouldScanForSecrets(contentType string, bodySize int, config Config) bool {
+	if bodySize == 0 {
+		return false
+	}
+	maxBytes := config.SecretScanMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultSecretScanMaxBytes
+	}
+	if bodySize > maxBytes {
+		return false
+	}
+	return isTextualContentType(contentType)
+}
+
+// deepSecretAssetPattern finds .js/.json/.txt asset references in a page's
+// src/href attributes, for -deep-secrets
+var deepSecretAssetPattern = regexp.MustCompile(`(?:src|href)\s*=\s*["']([^"']+\.(?:js|json|txt))(?:[?"'])`)
+
+// extractDeepSecretAssetURLs resolves every .js/.json/.txt asset referenced
+// in body against pageURL, for -deep-secrets' one-level-deep fetch. Assets
+// that resolve off pageURL's host (a third party, or an internal address
+// like a cloud metadata endpoint) are dropped -- same same-host guard
+// redirectRecursionSeed uses, since a page shouldn't be able to point the
+// scanner at a real request outside the engagement's target.
+func extractDeepSecretAssetURLs(pageURL, body string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	pageHost := extractHost(pageURL)
+	seen := make(map[string]bool)
+	var assets []string
+	for _, m := range deepSecretAssetPattern.FindAllStringSubmatch(body, -1) {
+		ref, err := url.Parse(m[1])
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref).String()
+		if extractHost(resolved) != pageHost {
+			continue
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			assets = append(assets, resolved)
+		}
+	}
+	return assets
+}
+
+// scanDeepSecretAssets implements -deep-secrets: fetches every linked
+// .js/.json/.txt asset referenced by a found page -- one level deep, not
+// recursive -- and runs full secret detection against each
+func scanDeepSecretAssets(pageURL, body, userAgent string, config Config) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, assetURL := range extractDeepSecretAssetURLs(pageURL, body) {
+		assetResult, assetBody, err := makeRequestWithUA(assetURL, "GET", userAgent, config)
+		if err != nil || assetResult.StatusCode != 200 || !shouldScanForSecrets(assetResult.ContentType, len(assetBody), config) {
+			continue
+		}
+		for _, secret := range detectSecrets(assetBody) {
+			if !seen[secret] {
+				seen[secret] = true
+				found = append(found, secret)
+			}
+		}
+	}
+	return found
+}
+
+func detectSecrets(content string) []string {
+	var foundSecrets []string
+	secretMap := make(map[string]bool)
+
+	for _, pattern := range secretPatterns {
+		if pattern.Pattern.MatchString(content) {
+			if !secretMap[pattern.Name] {
+				foundSecrets = append(foundSecrets, pattern.Name)
+				secretMap[pattern.Name] = true
+			}
+		}
+	}
+
+	return foundSecrets
+}
+
+var awsAccessKeyIDPattern = regexp.MustCompile(`\b((?:AKIA|ASIA|AIDA|AROA|AGPA|ANPA|ANVA|AIPA|ABIA|ACCA|ASCA|APKA)[A-Z0-9]{16})\b`)
+var pemBlockPattern = regexp.MustCompile(`-----BEGIN ((?:RSA |EC |OPENSSH |DSA )?PRIVATE KEY)-----\s*([\s\S]*?)-----END`)
+
+// classifySecretMaterial runs a second pass over content for the secret
+// types that have a verifiable internal structure (JWTs, PEM key blocks, AWS
+// access key IDs), producing a human-readable classification string per
+// match so the report can distinguish well-formed key material from regex
+// lookalikes. It does not contact any external service -- see -validate-secrets
+// for live credential verification.
+func classifySecretMaterial(content string, secretTypes []string) []string {
+	var notes []string
+	secretMap := make(map[string]bool)
+	for _, name := range secretTypes {
+		secretMap[name] = true
+	}
+
+	if secretMap["JWT Token"] {
+		if m := jwtPattern.FindString(content); m != "" {
+			notes = append(notes, classifyJWT(m))
+		}
+	}
+
+	if secretMap["Private Key"] {
+		notes = append(notes, classifyPEMBlock(content))
+	}
+
+	if secretMap["AWS Access Key"] {
+		if m := awsAccessKeyIDPattern.FindString(content); m != "" {
+			if awsKeyChecksumValid(m) {
+				notes = append(notes, "AWS Access Key: checksum valid (well-formed AWS key material)")
+			} else {
+				notes = append(notes, "AWS Access Key: checksum invalid (likely a lookalike, not a real AWS key)")
+			}
+		}
+	}
+
+	return notes
+}
+
+// classifyJWT decodes a JWT's header and payload (without verifying its
+// signature, since we have no key to verify against) to report the signing
+// algorithm and whether the token carries an expiry, which is often enough
+// to tell a real session token from a JWT-shaped test fixture
+func classifyJWT(token string) string {
+	parts := strings.SplitN(token, ".", 3)
+	if len(parts) != 3 {
+		return "JWT Token: malformed (expected header.payload.signature)"
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "JWT Token: header is not valid base64url"
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg == "" {
+		return "JWT Token: header is not valid JSON"
+	}
+
+	claimsNote := "no exp claim"
+	if payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+		var claims map[string]interface{}
+		if json.Unmarshal(payloadJSON, &claims) == nil {
+			if _, ok := claims["exp"]; ok {
+				claimsNote = "has exp claim"
+			}
+		}
+	}
+
+	return fmt.Sprintf("JWT Token: alg=%s, %s", header.Alg, claimsNote)
+}
+
+// classifyPEMBlock reports the declared key type and an approximate key size
+// from a PEM private-key block. The size is derived from the base64 body
+// length, not a real ASN.1 parse, so it is labeled approximate
+func classifyPEMBlock(content string) string {
+	m := pemBlockPattern.FindStringSubmatch(content)
+	if m == nil {
+		return "Private Key: PEM block present but unparsable"
+	}
+	keyType := strings.TrimSpace(m[1])
+	if keyType == "" {
+		keyType = "PRIVATE KEY"
+	}
+	body := strings.Join(strings.Fields(m[2]), "")
+	approxBits := (len(body) * 6 / 8 / 8) * 8
+	return fmt.Sprintf("%s: ~%d bits of key material (approximate, DER overhead not subtracted)", keyType, approxBits)
+}
+
+// awsKeyChecksumValid implements AWS's publicly documented access key ID
+// checksum format: trim the 4-character type prefix, base32-decode the
+// remaining 16 characters into 10 bytes, and verify the trailing 4 bytes are
+// a big-endian CRC32 of the leading 6. This only proves the string is
+// well-formed AWS key material versus a random lookalike -- it says nothing
+// about whether the key is still live
+func awsKeyChecksumValid(key string) bool {
+	if len(key) != 20 {
+		return false
+	}
+	decoded, err := base32.StdEncoding.DecodeString(key[4:])
+	if err != nil || len(decoded) != 10 {
+		return false
+	}
+	payload, checksum := decoded[:6], decoded[6:]
+	want := crc32.ChecksumIEEE(payload)
+	got := uint32(checksum[0])<<24 | uint32(checksum[1])<<16 | uint32(checksum[2])<<8 | uint32(checksum[3])
+	return want == got
+}
+
+// validateSecretMaterial is the -validate-secrets opt-in: for the providers
+// we can check without a destructive call, it hits the provider's own
+// identity/auth-check endpoint and reports which findings are confirmed
+// live. It never stores the provider's response, only a pass/fail
+func validateSecretMaterial(content string, secretTypes []string) []string {
+	var live []string
+	secretMap := make(map[string]bool)
+	for _, name := range secretTypes {
+		secretMap[name] = true
+	}
+
+	if secretMap["AWS Access Key"] {
+		if accessKeyID := awsAccessKeyIDPattern.FindString(content); accessKeyID != "" {
+			if m := awsSecretKeyPattern.FindStringSubmatch(content); m != nil {
+				if validateAWSKey(accessKeyID, m[1]) {
+					live = append(live, "AWS Access Key")
+				}
+			}
+		}
+	}
+
+	if secretMap["Slack Token"] {
+		if token := slackTokenPattern.FindString(content); token != "" {
+			if validateSlackToken(token) {
+				live = append(live, "Slack Token")
+			}
+		}
+	}
+
+	if secretMap["Google API Key"] {
+		if key := googleAPIKeyPattern.FindString(content); key != "" {
+			if validateGoogleAPIKey(key) {
+				live = append(live, "Google API Key")
+			}
+		}
+	}
+
+	return live
+}
+
+// validateAWSKey calls STS GetCallerIdentity, the standard non-destructive
+// way to check whether an AWS access key / secret key pair is live -- it
+// only confirms the caller's own identity and changes nothing in the account
+func validateAWSKey(accessKeyID, secretKey string) bool {
+	body := []byte("Action=GetCallerIdentity&Version=2011-06-15")
+	req, err := http.NewRequest("POST", "https://sts.amazonaws.com/", bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	req.Host = "sts.amazonaws.com"
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	signAWSRequestV4(req, body, accessKeyID, secretKey, "us-east-1", "sts")
+
+	resp, err := heavyHTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// signAWSRequestV4 signs req in-place using AWS Signature Version 4
+func signAWSRequestV4(req *http.Request, body []byte, accessKeyID, secretKey, region, service string) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.Host, payloadHash, amzDate)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.Path,
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", dateStamp, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := awsSignatureKey(secretKey, dateStamp, region, service)
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, credentialScope, signedHeaders, signature))
+}
+
+func awsSignatureKey(secretKey, dateStamp, region, service string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+secretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, region)
+	kService := hmacSHA256(kRegion, service)
+	return hmacSHA256(kService, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validateSlackToken calls auth.test, Slack's documented no-op credential
+// check -- it confirms the token works without performing any workspace action
+func validateSlackToken(token string) bool {
+	req, err := http.NewRequest("POST", "https://slack.com/api/auth.test", nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := heavyHTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var parsed struct {
+		Ok bool `json:"ok"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &parsed)
+	return parsed.Ok
+}
+
+// validateGoogleAPIKey calls a free, quota-only Google Discovery API
+// endpoint with the candidate key; a 200 means the key is accepted by
+// Google's API gateway, a 400/403 means it's invalid or revoked
+func validateGoogleAPIKey(key string) bool {
+	resp, err := heavyHTTPClient.Get("https://www.googleapis.com/discovery/v1/apis/discovery/v1/rest?key=" + url.QueryEscape(key))
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// bypassIP returns the address used to populate IP-trust bypass headers.
+// It never leaks the operator's real IP/hostname: by default it generates a
+// plausible random RFC1918 address per request, or -spoof-ip pins a fixed
+// value for engagements that need to test a specific internal trust boundary
+func bypassIP(config Config) string {
+	if config.SpoofIP != "" {
+		return config.SpoofIP
+	}
+	ranges := [][2]int{{10, 0}, {172, 16}, {192, 168}}
+	switch r := ranges[rand.Intn(len(ranges))]; r[0] {
+	case 10:
+		return fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), 1+rand.Intn(254))
+	case 172:
+		return fmt.Sprintf("172.%d.%d.%d", 16+rand.Intn(16), rand.Intn(256), 1+rand.Intn(254))
+	default:
+		return fmt.Sprintf("192.168.%d.%d", rand.Intn(256), 1+rand.Intn(254))
+	}
+}
+
+// attemptCanaryUpload uploads a harmless, uniquely-named canary file to a
+// detected upload form and checks whether the response reveals a retrieval
+// URL that serves it back unauthenticated, confirming an insecure upload
+func attemptCanaryUpload(targetURL, path, userAgent string, config Config) (*Result, string) {
+	canaryToken := randomHex(12)
+	canaryName := fmt.Sprintf("capsaicin-canary-%s.txt", canaryToken)
+	canaryContent := "capsaicin-canary:" + canaryToken
+	uploadURL := buildURL(targetURL, path)
+	recordCanary("upload-probe", canaryName, uploadURL)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", canaryName)
+	if err != nil {
+		return nil, ""
+	}
+	if _, err := part.Write([]byte(canaryContent)); err != nil {
+		return nil, ""
+	}
+	if err := writer.Close(); err != nil {
+		return nil, ""
+	}
+
+	req, err := http.NewRequest("POST", uploadURL, &body)
+	if err != nil {
+		return nil, ""
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", userAgent)
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+	applyLoginToken(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, ""
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ""
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusFound {
+		return nil, ""
+	}
+
+	retrievalPath := findUploadRetrievalPath(string(respBody), canaryName)
+	if retrievalPath == "" {
+		return nil, ""
+	}
+
+	retrievalURL := buildURL(targetURL, retrievalPath)
+	verifyResult, verifyBody, err := makeRequestWithUA(retrievalURL, "GET", userAgent, config)
+	if err != nil || verifyResult.StatusCode != http.StatusOK || !strings.Contains(verifyBody, canaryToken) {
+		return nil, ""
+	}
+
+	verifyResult.Critical = true
+	verifyResult.Tags = append(verifyResult.Tags, "unauthenticated-upload")
+	return verifyResult, verifyBody
+}
+
+// findUploadRetrievalPath looks for a quoted path in an upload response that
+// references the canary filename, the common way apps echo back where a
+// just-uploaded file landed
+func findUploadRetrievalPath(body, filename string) string {
+	pattern := regexp.MustCompile(`["']([^"']*` + regexp.QuoteMeta(filename) + `)["']`)
+	m := pattern.FindStringSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+func attemptBypass(url, userAgent string, config Config) (*Result, string) {
+	spoofedIP := bypassIP(config)
+	bypassHeaders := map[string]string{
+		"X-Forwarded-For":           spoofedIP,
+		"X-Original-URL":            extractPath(url),
+		"X-Rewrite-URL":             extractPath(url),
+		"X-Custom-IP-Authorization": spoofedIP,
+		"Client-IP":                 spoofedIP,
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, ""
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	// Apply custom headers first
+	for key, value := range config.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+	applyLoginToken(req)
+
+	// Then apply bypass headers
+	for key, value := range bypassHeaders {
+		req.Header.Set(key, value)
+		recordCanary("bypass-header", key+": "+value, url)
+	}
+
+	requestStart := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, ""
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ""
+	}
+	responseTime := time.Since(requestStart)
+
+	bodyContent := string(body)
+	server := resp.Header.Get("Server")
+	poweredBy := resp.Header.Get("X-Powered-By")
+
+	curlCmd := fmt.Sprintf(`curl -X GET "%s" -H "User-Agent: %s" -H "X-Forwarded-For: %s" -H "X-Original-URL: %s"`,
+		url, userAgent, spoofedIP, extractPath(url))
+
+	result := &Result{
+		URL:             url,
+		StatusCode:      resp.StatusCode,
+		Size:            len(body),
+		WordCount:       len(strings.Fields(bodyContent)),
+		LineCount:       strings.Count(bodyContent, "\n") + 1,
+		Method:          "GET",
+		Timestamp:       time.Now().Format(time.RFC3339),
+		Server:          server,
+		PoweredBy:       poweredBy,
+		UserAgent:       userAgent,
+		CurlCommand:     curlCmd,
+		BypassTechnique: "header-spoof",
+		ResponseTimeMs:  responseTime.Milliseconds(),
+	}
+
+	if !config.LocalMode {
+		if wafName := detectWAF(resp, bodyContent); wafName != "" {
+			result.WAFDetected = wafName
+		}
+	}
+
+	return result, bodyContent
+}
+
+// NEW FEATURE: Unicode/whitespace path obfuscation for WAF bypass
+func generateObfuscations(path string) map[string]string {
+	return map[string]string{
+		"encoded-slash":   strings.ReplaceAll(path, "/", "%2f"),
+		"overlong-utf8":   strings.ReplaceAll(path, "/", "%c0%af"),
+		"trailing-lf":     path + "%0a",
+		"trailing-tab":    path + "%09",
+		"fullwidth-slash": strings.ReplaceAll(path, "/", "／"),
+	}
+}
+
+// attemptObfuscationBypass retries a WAF-blocked path using obfuscated
+// variants, returning the first one that produces a non-blocked response
+func attemptObfuscationBypass(targetURL, path, userAgent string, config Config, signatures []ResponseSignature) (*Result, string) {
+	for name, variant := range generateObfuscations(path) {
+		url := buildURL(targetURL, variant)
+		result, body, err := makeRequestWithUA(url, "GET", userAgent, config)
+		if err != nil {
+			continue
+		}
+		if isInteresting(result, config) && !matchesSignature(result, signatures) && result.StatusCode != 403 {
+			result.Obfuscation = name
+			result.Critical = true
+			return result, body
+		}
+	}
+	return nil, ""
+}
+
+// attemptProtocolDowngrade retries a blocked URL with a hand-rolled
+// HTTP/1.0 request sent over a raw connection, bypassing net/http
+// entirely so the Host header goes out exactly as given in the URL (no
+// normalization) and no HTTP/1.1 framing is advertised. Some WAFs and
+// older backends only apply their blocking rules on the HTTP/1.1 path
+func attemptProtocolDowngrade(targetURL, userAgent string, config Config) (*Result, string, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	addr := parsed.Host
+	if !strings.Contains(addr, ":") {
+		if parsed.Scheme == "https" {
+			addr += ":443"
+		} else {
+			addr += ":80"
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: time.Duration(config.Timeout) * time.Second}
+	var conn net.Conn
+	if parsed.Scheme == "https" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: parsed.Hostname()})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Duration(config.Timeout) * time.Second))
+
+	requestURI := parsed.RequestURI()
+	rawRequest := fmt.Sprintf("GET %s HTTP/1.0\r\nHost: %s\r\nUser-Agent: %s\r\nAccept: */*\r\nConnection: close\r\n\r\n",
+		requestURI, parsed.Host, userAgent)
+	if _, err := conn.Write([]byte(rawRequest)); err != nil {
+		return nil, "", err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "GET"})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	bodyContent := string(body)
+
+	result := &Result{
+		URL:        targetURL,
+		StatusCode: resp.StatusCode,
+		Size:       len(body),
+		WordCount:  len(strings.Fields(bodyContent)),
+		LineCount:  strings.Count(bodyContent, "\n") + 1,
+		Method:     "GET",
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Server:     resp.Header.Get("Server"),
+		PoweredBy:  resp.Header.Get("X-Powered-By"),
+		UserAgent:  userAgent,
+		Protocol:   "HTTP/1.0",
+	}
+
+	return result, bodyContent, nil
+}
+
+// generateMutations returns named path/query variants of a confirmed
+// finding to probe for inconsistent access control or caching: file-backup
+// suffixes, duplicated/dot-segment path tricks, and query parameter
+// pollution. The map key names the technique, surfaced on the mutated
+// Result so -o/-html readers can tell which variant triggered it.
+func generateMutations(path string) map[string]string {
+	mutations := map[string]string{
+		"bak-suffix":        path + ".bak",
+		"old-suffix":        path + ".old",
+		"backup-suffix":     path + ".backup",
+		"tilde-suffix":      path + "~",
+		"swp-suffix":        path + ".swp",
+		"dot-swp":           "." + path + ".swp",
+		"underscore-prefix": "_" + path,
+		"txt-suffix":        path + ".txt",
+		"orig-suffix":       path + ".orig",
+	}
+
+	if strings.Contains(path, ".") {
+		parts := strings.Split(path, ".")
+		base := strings.Join(parts[:len(parts)-1], ".")
+		mutations["bak-before-ext"] = base + ".bak." + parts[len(parts)-1]
+	}
+
+	pathPart, query := path, ""
+	if idx := strings.IndexByte(path, '?'); idx != -1 {
+		pathPart, query = path[:idx], path[idx+1:]
+	}
+
+	trimmed := strings.TrimSuffix(pathPart, "/")
+	segment := trimmed
+	if idx := strings.LastIndex(trimmed, "/"); idx != -1 {
+		segment = trimmed[idx+1:]
+	}
+	if segment != "" {
+		mutations["duplicated-segment"] = trimmed + "/" + segment
+		mutations["dot-segment"] = trimmed + "/./" + segment
+		mutations["dot-dot-segment"] = trimmed + "/../" + segment
+	}
+
+	if query != "" {
+		mutations["param-pollution"] = path + "&" + query
+	} else {
+		mutations["param-pollution"] = path + "?a=1&a=2"
+	}
+
+	return mutations
+}
+
+// redirectRecursionSeed resolves a 301/302's Location header against the
+// URL that produced it and, if it resolves to the same host, returns the
+// destination's path -- the canonical directory to recurse into. A plain
+// isDirectory/extractPath pair only sees the requested path (e.g.
+// "/admin"), losing the "/admin/" the server actually redirected to.
+func redirectRecursionSeed(requestURL, location string) (string, bool) {
+	if location == "" {
+		return "", false
+	}
+	resolved, err := resolveRedirectURL(requestURL, location)
+	if err != nil {
+		return "", false
+	}
+	if extractHost(resolved) != extractHost(requestURL) {
+		return "", false
+	}
+	return extractPath(resolved), true
+}
+
+func isDirectory(result *Result) bool {
+	if result.StatusCode == 301 || result.StatusCode == 302 || result.StatusCode == 403 {
+		return true
+	}
+	if strings.HasSuffix(result.URL, "/") {
+		return true
+	}
+	return false
+}
+
+func matchesSignature(result *Result, signatures []ResponseSignature) bool {
+	for _, sig := range signatures {
+		if result.StatusCode == sig.StatusCode {
+			if sig.Size == 0 {
+				continue
+			}
+			sizeDiff := float64(abs(result.Size-sig.Size)) / float64(sig.Size)
+			if sizeDiff < 0.05 {
+				return true
+			}
+			if bits.OnesCount64(result.simHash^sig.SimHash) <= soft404SimHashThreshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInteresting decides whether a result counts as a finding. -fc/-fs/-fw/-fl
+// drop responses outright (checked first, so a response can never be both
+// filtered and matched), then -mc/-ms/-mw/-ml -- if any are set -- become the
+// sole criterion instead of the default 2xx/3xx/401/403 heuristic, on top of
+// the existing signature-based auto-calibration.
+func isInteresting(result *Result, config Config) bool {
+	if len(config.FilterCodeRanges) > 0 && matchesIntRanges(result.StatusCode, config.FilterCodeRanges) {
+		return false
+	}
+	if len(config.FilterSizeRanges) > 0 && matchesIntRanges(result.Size, config.FilterSizeRanges) {
+		return false
+	}
+	if len(config.FilterWordRanges) > 0 && matchesIntRanges(result.WordCount, config.FilterWordRanges) {
+		return false
+	}
+	if len(config.FilterLineRanges) > 0 && matchesIntRanges(result.LineCount, config.FilterLineRanges) {
+		return false
+	}
+	if len(config.FilterContentTypes) > 0 && matchesContentType(result.ContentType, config.FilterContentTypes) {
+		return false
+	}
+	if len(config.FilterProtocols) > 0 && slices.Contains(config.FilterProtocols, result.Protocol) {
+		return false
+	}
+
+	hasMatcher := len(config.MatchCodeRanges) > 0 || len(config.MatchSizeRanges) > 0 ||
+		len(config.MatchWordRanges) > 0 || len(config.MatchLineRanges) > 0 || len(config.MatchContentTypes) > 0 ||
+		len(config.MatchProtocols) > 0
+	if hasMatcher {
+		if len(config.MatchCodeRanges) > 0 && !matchesIntRanges(result.StatusCode, config.MatchCodeRanges) {
+			return false
+		}
+		if len(config.MatchSizeRanges) > 0 && !matchesIntRanges(result.Size, config.MatchSizeRanges) {
+			return false
+		}
+		if len(config.MatchWordRanges) > 0 && !matchesIntRanges(result.WordCount, config.MatchWordRanges) {
+			return false
+		}
+		if len(config.MatchLineRanges) > 0 && !matchesIntRanges(result.LineCount, config.MatchLineRanges) {
+			return false
+		}
+		if len(config.MatchContentTypes) > 0 && !matchesContentType(result.ContentType, config.MatchContentTypes) {
+			return false
+		}
+		if len(config.MatchProtocols) > 0 && !slices.Contains(config.MatchProtocols, result.Protocol) {
+			return false
+		}
+		return true
+	}
+
+	if result.StatusCode >= 200 && result.StatusCode < 400 {
+		return true
+	}
+	if result.StatusCode == 401 || result.StatusCode == 403 {
+		return true
+	}
+	return false
+}
+
+// matchesContentType reports whether a result's Content-Type equals, or
+// starts with (for the "type/" wildcard shorthand), any of the wanted MIME
+// types, e.g. "application/json" or "image/" to match the whole image/* family
+func matchesContentType(contentType string, wanted []string) bool {
+	for _, want := range wanted {
+		if contentType == want || (strings.HasSuffix(want, "/") && strings.HasPrefix(contentType, want)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesBodyFilters applies -fr/-mr to the response body, separately from
+// isInteresting's status/size/word/line criteria since only this call site
+// has the body in hand. On a -mr match, it records the matched string on
+// result so the report shows what triggered the finding.
+func matchesBodyFilters(body string, config Config, result *Result) bool {
+	if config.FilterBodyRegex != nil && config.FilterBodyRegex.MatchString(body) {
+		return false
+	}
+	if config.MatchBodyRegex != nil {
+		match := config.MatchBodyRegex.FindString(body)
+		if match == "" {
+			return false
+		}
+		result.BodyMatch = match
+	}
+	return true
+}
+
+// groupedPrinter buffers findings for -group-output and periodically flushes
+// them grouped by target, then by severity (criticals first), so humans
+// watching a multi-target scan see coherent blocks instead of interleaved noise
+type groupedPrinter struct {
+	mu       sync.Mutex
+	byTarget map[string][]Result
+}
+
+func newGroupedPrinter() *groupedPrinter {
+	return &groupedPrinter{byTarget: make(map[string][]Result)}
+}
+
+// targetOf extracts the scheme+host portion of a result's URL for grouping
+func targetOf(result Result) string {
+	parsed, err := url.Parse(result.URL)
+	if err != nil {
+		return result.URL
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+func (g *groupedPrinter) add(result Result) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	target := targetOf(result)
+	g.byTarget[target] = append(g.byTarget[target], result)
+}
+
+func (g *groupedPrinter) flush() {
+	g.mu.Lock()
+	if len(g.byTarget) == 0 {
+		g.mu.Unlock()
+		return
+	}
+	grouped := g.byTarget
+	g.byTarget = make(map[string][]Result)
+	g.mu.Unlock()
+
+	targets := make([]string, 0, len(grouped))
+	for target := range grouped {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		results := grouped[target]
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Critical && !results[j].Critical
+		})
+		fmt.Printf("\n%s── %s ──%s\n", ColorNeonCyan+ColorBold, target, ColorReset)
+		for _, r := range results {
+			printResult(r)
+		}
+	}
+}
+
+func (g *groupedPrinter) run(ctx context.Context, flushEvery time.Duration) {
+	ticker := time.NewTicker(flushEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			g.flush()
+			return
+		case <-ticker.C:
+			g.flush()
+		}
+	}
+}
+
+// printCookieAudit aggregates the cookie issues found across every response
+// into a per-target security summary printed at the end of the scan
+func printCookieAudit(results []Result) {
+	byTarget := make(map[string]map[string]bool)
+	var targets []string
+	for _, r := range results {
+		if len(r.CookieIssues) == 0 {
+			continue
+		}
+		target := targetOf(r)
+		if byTarget[target] == nil {
+			byTarget[target] = make(map[string]bool)
+			targets = append(targets, target)
+		}
+		for _, issue := range r.CookieIssues {
+			byTarget[target][issue] = true
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	sort.Strings(targets)
+
+	fmt.Printf("%s┌─ COOKIE / SESSION AUDIT ─────────────────────────────────────┐%s\n", ColorCyan, ColorReset)
+	for _, target := range targets {
+		fmt.Printf("%s│%s %s\n", ColorCyan, ColorReset, target)
+		issues := make([]string, 0, len(byTarget[target]))
+		for issue := range byTarget[target] {
+			issues = append(issues, issue)
+		}
+		sort.Strings(issues)
+		for _, issue := range issues {
+			fmt.Printf("%s│%s   %s⚠%s  %s\n", ColorCyan, ColorReset, ColorYellow, ColorReset, issue)
+		}
+	}
+	fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+}
+
+// printCSPAudit aggregates CSP weaknesses and third-party origins found
+// across every response into a per-target report printed at scan end
+func printCSPAudit(results []Result) {
+	issuesByTarget := make(map[string]map[string]bool)
+	originsByTarget := make(map[string]map[string]bool)
+	var targets []string
+	for _, r := range results {
+		if len(r.CSPIssues) == 0 && len(r.CSPThirdPartyOrigins) == 0 {
+			continue
+		}
+		target := targetOf(r)
+		if issuesByTarget[target] == nil {
+			issuesByTarget[target] = make(map[string]bool)
+			originsByTarget[target] = make(map[string]bool)
+			targets = append(targets, target)
+		}
+		for _, issue := range r.CSPIssues {
+			issuesByTarget[target][issue] = true
+		}
+		for _, origin := range r.CSPThirdPartyOrigins {
+			originsByTarget[target][origin] = true
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	sort.Strings(targets)
+
+	fmt.Printf("%s┌─ CSP WEAKNESS REPORT ────────────────────────────────────────┐%s\n", ColorCyan, ColorReset)
+	for _, target := range targets {
+		fmt.Printf("%s│%s %s\n", ColorCyan, ColorReset, target)
+		issues := make([]string, 0, len(issuesByTarget[target]))
+		for issue := range issuesByTarget[target] {
+			issues = append(issues, issue)
+		}
+		sort.Strings(issues)
+		for _, issue := range issues {
+			fmt.Printf("%s│%s   %s⚠%s  %s\n", ColorCyan, ColorReset, ColorYellow, ColorReset, issue)
+		}
+
+		origins := make([]string, 0, len(originsByTarget[target]))
+		for origin := range originsByTarget[target] {
+			origins = append(origins, origin)
+		}
+		if len(origins) > 0 {
+			sort.Strings(origins)
+			fmt.Printf("%s│%s   third-party origins: %s\n", ColorCyan, ColorReset, strings.Join(origins, ", "))
+		}
+	}
+	fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+}
+
+// printMethodHeaderDiffReport aggregates the per-method header discrepancies
+// surfaced during method-fuzz (method-matrix) runs into a per-target summary
+// printed at the end of the scan
+func printMethodHeaderDiffReport(results []Result) {
+	byTarget := make(map[string]map[string]bool)
+	var targets []string
+	for _, r := range results {
+		if len(r.MethodHeaderDiffs) == 0 {
+			continue
+		}
+		target := targetOf(r)
+		if byTarget[target] == nil {
+			byTarget[target] = make(map[string]bool)
+			targets = append(targets, target)
+		}
+		for _, diff := range r.MethodHeaderDiffs {
+			byTarget[target][diff] = true
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	sort.Strings(targets)
+
+	fmt.Printf("%s┌─ METHOD HEADER DIFF REPORT ──────────────────────────────────┐%s\n", ColorCyan, ColorReset)
+	for _, target := range targets {
+		fmt.Printf("%s│%s %s\n", ColorCyan, ColorReset, target)
+		diffs := make([]string, 0, len(byTarget[target]))
+		for diff := range byTarget[target] {
+			diffs = append(diffs, diff)
+		}
+		sort.Strings(diffs)
+		for _, diff := range diffs {
+			fmt.Printf("%s│%s   %s⚠%s  %s\n", ColorCyan, ColorReset, ColorYellow, ColorReset, diff)
+		}
+	}
+	fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+}
+
+// WordlistSourceStats reports hit-rate statistics for one task source
+// ("wordlist", "recursion", "method-fuzz", "bypass", ...) so teams can judge
+// which discovery passes are pulling their weight
+type WordlistSourceStats struct {
+	Source string   `json:"source"`
+	Hits   int      `json:"hits"`
+	Words  []string `json:"words"`
+}
+
+// WordlistEfficacyReport is the exportable shape written by -wordlist-report
+type WordlistEfficacyReport struct {
+	TotalFindings int                   `json:"total_findings"`
+	BySource      []WordlistSourceStats `json:"by_source"`
+	WordHitCounts map[string]int        `json:"word_hit_counts"`
+}
+
+// buildWordlistEfficacyReport aggregates which wordlist entries and which
+// task sources actually produced findings, so operators can trim a bloated
+// wordlist down to the entries that pay off across real engagements
+func buildWordlistEfficacyReport(results []Result) WordlistEfficacyReport {
+	bySource := make(map[string]*WordlistSourceStats)
+	var sourceOrder []string
+	wordHits := make(map[string]int)
+
+	for _, r := range results {
+		if r.Source == "" {
+			continue
+		}
+		if bySource[r.Source] == nil {
+			bySource[r.Source] = &WordlistSourceStats{Source: r.Source}
+			sourceOrder = append(sourceOrder, r.Source)
+		}
+		stat := bySource[r.Source]
+		stat.Hits++
+		if r.Word != "" {
+			wordHits[r.Word]++
+		}
+	}
+
+	sort.Strings(sourceOrder)
+	report := WordlistEfficacyReport{
+		TotalFindings: len(results),
+		WordHitCounts: wordHits,
+	}
+	for _, source := range sourceOrder {
+		stat := bySource[source]
+		words := make([]string, 0, len(wordHits))
+		seen := make(map[string]bool)
+		for _, r := range results {
+			if r.Source == source && r.Word != "" && !seen[r.Word] {
+				seen[r.Word] = true
+				words = append(words, r.Word)
+			}
+		}
+		sort.Strings(words)
+		stat.Words = words
+		report.BySource = append(report.BySource, *stat)
+	}
+	return report
+}
+
+// printWordlistEfficacyReport prints a per-source hit-rate summary and the
+// top words responsible for the most findings, mirroring the other
+// end-of-scan audit reports
+func printWordlistEfficacyReport(report WordlistEfficacyReport) {
+	if len(report.BySource) == 0 {
+		return
+	}
+
+	fmt.Printf("%s┌─ WORDLIST EFFICACY REPORT ───────────────────────────────────┐%s\n", ColorCyan, ColorReset)
+	for _, stat := range report.BySource {
+		fmt.Printf("%s│%s %s: %d hit(s)\n", ColorCyan, ColorReset, stat.Source, stat.Hits)
+	}
+
+	type wordCount struct {
+		Word string
+		N    int
+	}
+	topWords := make([]wordCount, 0, len(report.WordHitCounts))
+	for word, n := range report.WordHitCounts {
+		topWords = append(topWords, wordCount{word, n})
+	}
+	sort.Slice(topWords, func(i, j int) bool {
+		if topWords[i].N != topWords[j].N {
+			return topWords[i].N > topWords[j].N
+		}
+		return topWords[i].Word < topWords[j].Word
+	})
+	if len(topWords) > 10 {
+		topWords = topWords[:10]
+	}
+	if len(topWords) > 0 {
+		fmt.Printf("%s│%s top words:\n", ColorCyan, ColorReset)
+		for _, wc := range topWords {
+			fmt.Printf("%s│%s   %s%-30s%s %d\n", ColorCyan, ColorReset, ColorWhite, wc.Word, ColorReset, wc.N)
+		}
+	}
+	fmt.Printf("%s└──────────────────────────────────────────────────────────────┘%s\n\n", ColorCyan, ColorReset)
+}
+
+// saveWordlistEfficacyReport writes the report as JSON so it can be
+// diffed across engagements or fed into a wordlist-trimming pipeline
+func saveWordlistEfficacyReport(report WordlistEfficacyReport, filename string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func printResult(result Result) {
+	var color string
+	switch {
+	case result.StatusCode >= 200 && result.StatusCode < 300:
+		color = ColorNeonGreen
+	case result.StatusCode >= 300 && result.StatusCode < 400:
+		color = ColorBlue
+	case result.StatusCode >= 400 && result.StatusCode < 500:
+		color = ColorRed
+	case result.StatusCode >= 500:
+		color = ColorYellow
+	default:
+		color = ColorWhite
+	}
+
+	critical := ""
+	if result.Critical {
+		critical = ColorOrange + ColorBold + " [⚡ CRITICAL]" + ColorReset
+	}
+
+	// WAF Detection output with high visibility
+	wafInfo := ""
+	if result.WAFDetected != "" {
+		wafInfo = BgMagenta + ColorWhite + ColorBold + " [🔥 WAF: " + result.WAFDetected + "] " + ColorReset
+	}
+
+	secretInfo := ""
+	if result.SecretFound {
+		secretInfo = BgRed + ColorWhite + ColorBold + " [🔐 " + strings.Join(result.SecretTypes, ", ") + "] " + ColorReset
+		if len(result.SecretClassification) > 0 {
+			secretInfo += ColorRed + " (" + strings.Join(result.SecretClassification, "; ") + ") " + ColorReset
+		}
+		if len(result.LiveSecrets) > 0 {
+			secretInfo += BgRed + ColorWhite + ColorBold + " [☠️ LIVE: " + strings.Join(result.LiveSecrets, ", ") + "] " + ColorReset
+		}
+	}
+
+	piiInfo := ""
+	if result.PIIFound {
+		piiInfo = ColorOrange + ColorBold + " [🪪 PII: " + strings.Join(result.PIITypes, ", ") + "]" + ColorReset
+	}
+
+	infoDisclosureInfo := ""
+	if len(result.InfoDisclosure) > 0 {
+		infoDisclosureInfo = ColorYellow + " [🕵 INFO LEAK: " + strings.Join(result.InfoDisclosure, ", ") + "]" + ColorReset
+	}
+
+	headDiffInfo := ""
+	if result.HeadGetDiscrepancy != "" {
+		headDiffInfo = ColorPurple + " [HEAD/GET DIFF: " + result.HeadGetDiscrepancy + "]" + ColorReset
+	}
+
+	tagInfo := ""
+	if len(result.Tags) > 0 {
+		tagInfo = ColorNeonGreen + " [🏷  " + strings.Join(result.Tags, ", ") + "]" + ColorReset
+	}
+
+	obfInfo := ""
+	if result.Obfuscation != "" {
+		obfInfo = ColorPink + " [OBFUSCATED: " + result.Obfuscation + "]" + ColorReset
+	}
+
+	mismatchInfo := ""
+	if result.LengthMismatch {
+		mismatchInfo = ColorYellow + ColorBold + fmt.Sprintf(" [LEN MISMATCH: hdr=%d body=%d]", result.ContentLengthHeader, result.Size) + ColorReset
+	}
+
+	methodInfo := ""
+	if result.Method != "GET" {
+		methodInfo = ColorPurple + " [METHOD: " + result.Method + "]" + ColorReset
+	}
+
+	bypassInfo := ""
+	if result.BypassTechnique != "" {
+		bypassInfo = ColorPink + " [BYPASS: " + result.BypassTechnique + "]" + ColorReset
+	}
+
+	techInfo := ""
+	if result.Server != "" {
+		techInfo += fmt.Sprintf(" [%s]", result.Server)
+	}
+	if result.PoweredBy != "" {
+		techInfo += fmt.Sprintf(" [%s]", result.PoweredBy)
+	}
 
-			// Active 403/401 Bypass
-			if result.StatusCode == 403 || result.StatusCode == 401 {
-				bypassResult, bypassBody := attemptBypass(url, userAgent, config)
-				if bypassResult != nil && (bypassResult.StatusCode == 200 || bypassResult.StatusCode == 302) {
-					bypassResult.Critical = true
+	authInfo := ""
+	if result.AuthScheme != "" {
+		authInfo = ColorPurple + fmt.Sprintf(" [AUTH: %s", result.AuthScheme)
+		if result.AuthRealm != "" {
+			authInfo += fmt.Sprintf(" realm=%q", result.AuthRealm)
+		}
+		authInfo += "]" + ColorReset
+	}
 
-					if secrets := detectSecrets(bypassBody); len(secrets) > 0 {
-						bypassResult.SecretFound = true
-						bypassResult.SecretTypes = secrets
-						atomic.AddInt64(&stats.Secrets, 1)
-					}
+	bodyMatchInfo := ""
+	if result.BodyMatch != "" {
+		bodyMatchInfo = ColorNeonGreen + fmt.Sprintf(" [-mr: %q]", result.BodyMatch) + ColorReset
+	}
 
-					results <- *bypassResult
+	headerAnomalyInfo := ""
+	if len(result.HeaderAnomalies) > 0 {
+		headerAnomalyInfo = ColorYellow + " [⚠ HEADER LEAK: " + strings.Join(result.HeaderAnomalies, "; ") + "]" + ColorReset
+	}
 
-					mutations := generateMutations(task.Path)
-					for _, mutation := range mutations {
-						mutatedURL := strings.TrimSuffix(task.TargetURL, "/") + "/" + strings.TrimPrefix(mutation, "/")
-						mutatedResult, mutatedBody, err := makeRequestWithUA(mutatedURL, "GET", userAgent, config)
-						if err == nil && isInteresting(mutatedResult) && !matchesSignature(mutatedResult, targetSignatures[task.TargetURL]) {
-							if secrets := detectSecrets(mutatedBody); len(secrets) > 0 {
-								mutatedResult.SecretFound = true
-								mutatedResult.SecretTypes = secrets
-								atomic.AddInt64(&stats.Secrets, 1)
-							}
-							results <- *mutatedResult
-						}
-					}
-				}
-			}
+	fmt.Printf("%s%-4d%s │ Size: %s%-7d%s │ %s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s%s\n",
+		color+ColorBold, result.StatusCode, ColorReset,
+		color, result.Size, ColorReset,
+		color, result.URL, ColorReset,
+		ColorCyan+techInfo+ColorReset,
+		methodInfo,
+		bypassInfo,
+		critical,
+		wafInfo,
+		secretInfo,
+		piiInfo,
+		tagInfo,
+		obfInfo,
+		mismatchInfo,
+		authInfo,
+		bodyMatchInfo,
+		headerAnomalyInfo,
+		infoDisclosureInfo,
+		headDiffInfo)
+}
 
-			// Recursive Discovery
-			if config.MaxDepth > 0 && task.Depth < config.MaxDepth {
-				if isDirectory(result) {
-					dirPath := extractPath(url)
-					if config.Verbose {
-						fmt.Printf("%s[RECURSE]%s Found directory: %s (Depth: %d)\n",
-							ColorYellow, ColorReset, dirPath, task.Depth)
-					}
-					newTasks <- Task{
-						TargetURL: task.TargetURL,
-						Path:      dirPath,
-						Depth:     task.Depth + 1,
-					}
-				}
-			}
+// NEW FEATURE: Signal-driven runtime stats dump. Sending SIGUSR1 prints a
+// detailed snapshot to stderr without interrupting the scan, useful for
+// diagnosing long-running scans with no dashboard attached
+func watchStatsSignal(stats *Stats, taskChan chan Task, resultChan chan Result, targets []string) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR1)
 
-			results <- *result
-		}
+	for range sigChan {
+		printStatsSnapshot(stats, taskChan, resultChan, targets, os.Stderr)
 	}
 }
 
-func makeRequestWithUA(url, method, userAgent string, config Config) (*Result, string, error) {
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, "", err
+// printStatsSnapshot writes the same detailed counters dump used by both
+// SIGUSR1 (watchStatsSignal) and the "s" hotkey under -keyboard-controls
+// (watchKeyboardControls)
+func printStatsSnapshot(stats *Stats, taskChan chan Task, resultChan chan Result, targets []string, out io.Writer) {
+	elapsed := time.Since(stats.StartTime)
+	processed := atomic.LoadInt64(&stats.Processed)
+	reqPerSec := float64(processed) / elapsed.Seconds()
+
+	fmt.Fprintf(out, "\n%s=== STATS SNAPSHOT (%s) ===%s\n", ColorNeonCyan+ColorBold, time.Now().Format(time.RFC3339), ColorReset)
+	fmt.Fprintf(out, "Targets:        %d\n", len(targets))
+	fmt.Fprintf(out, "Elapsed:        %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(out, "Processed:      %d / %d\n", processed, atomic.LoadInt64(&stats.Total))
+	fmt.Fprintf(out, "Rate:           %.2f req/s\n", reqPerSec)
+	fmt.Fprintf(out, "Found:          %d\n", atomic.LoadInt64(&stats.Found))
+	fmt.Fprintf(out, "Errors:         %d\n", atomic.LoadInt64(&stats.Errors))
+	fmt.Fprintf(out, "Secrets:        %d\n", atomic.LoadInt64(&stats.Secrets))
+	fmt.Fprintf(out, "PII Found:      %d\n", atomic.LoadInt64(&stats.PII))
+	fmt.Fprintf(out, "WAF Hits:       %d\n", atomic.LoadInt64(&stats.WAFHits))
+	fmt.Fprintf(out, "Throttled:      %d\n", atomic.LoadInt64(&stats.Throttled))
+	fmt.Fprintf(out, "Destructive Skipped: %d\n", atomic.LoadInt64(&stats.DestructiveSkipped))
+	fmt.Fprintf(out, "Task Queue:     %d / %d\n", len(taskChan), cap(taskChan))
+	fmt.Fprintf(out, "Result Queue:   %d / %d\n", len(resultChan), cap(resultChan))
+	fmt.Fprintf(out, "%s==============================%s\n\n", ColorNeonCyan+ColorBold, ColorReset)
+}
+
+// startMetricsServer exposes runtime health counters in the Prometheus text
+// exposition format, for -metrics-addr. It is a best-effort diagnostic
+// surface, not a full scan API: scrape failures just mean no telemetry for
+// that interval, nothing in the scan itself depends on it being reachable
+// latencySamplesMax caps the /metrics rolling latency window: a running
+// scan doesn't have buildScanSummary's full-run, per-target view, so
+// /metrics tracks a bounded recent window across all targets instead
+const latencySamplesMax = 2000
+
+var latencySamplesMutex sync.Mutex
+var latencySamples []int64
+
+// recordLatencySample appends ms to the /metrics rolling latency window,
+// evicting the oldest sample once it's full
+func recordLatencySample(ms int64) {
+	latencySamplesMutex.Lock()
+	defer latencySamplesMutex.Unlock()
+	latencySamples = append(latencySamples, ms)
+	if len(latencySamples) > latencySamplesMax {
+		latencySamples = latencySamples[len(latencySamples)-latencySamplesMax:]
 	}
+}
 
-	req.Header.Set("User-Agent", userAgent)
+// latencyPercentilesSnapshot returns p50/p95/p99 across the current
+// /metrics rolling latency window
+func latencyPercentilesSnapshot() (p50, p95, p99 int64) {
+	latencySamplesMutex.Lock()
+	samples := make([]int64, len(latencySamples))
+	copy(samples, latencySamples)
+	latencySamplesMutex.Unlock()
 
-	// NEW FEATURE: Apply custom headers to ALL requests
-	for key, value := range config.CustomHeaders {
-		req.Header.Set(key, value)
+	if len(samples) == 0 {
+		return 0, 0, 0
 	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return latencyPercentile(samples, 50), latencyPercentile(samples, 95), latencyPercentile(samples, 99)
+}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, "", err
+func startMetricsServer(addr string, stats *Stats, taskChan chan Task, resultChan chan Result) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintf(w, "capsaicin_goroutines %d\n", runtime.NumGoroutine())
+		fmt.Fprintf(w, "capsaicin_heap_alloc_bytes %d\n", mem.HeapAlloc)
+		fmt.Fprintf(w, "capsaicin_heap_objects %d\n", mem.HeapObjects)
+		fmt.Fprintf(w, "capsaicin_gc_runs_total %d\n", mem.NumGC)
+		fmt.Fprintf(w, "capsaicin_task_queue_depth %d\n", len(taskChan))
+		fmt.Fprintf(w, "capsaicin_task_queue_capacity %d\n", cap(taskChan))
+		fmt.Fprintf(w, "capsaicin_result_queue_depth %d\n", len(resultChan))
+		fmt.Fprintf(w, "capsaicin_result_queue_capacity %d\n", cap(resultChan))
+		fmt.Fprintf(w, "capsaicin_requests_processed_total %d\n", atomic.LoadInt64(&stats.Processed))
+		fmt.Fprintf(w, "capsaicin_requests_total %d\n", atomic.LoadInt64(&stats.Total))
+		fmt.Fprintf(w, "capsaicin_errors_total %d\n", atomic.LoadInt64(&stats.Errors))
+		fmt.Fprintf(w, "capsaicin_findings_total %d\n", atomic.LoadInt64(&stats.Found))
+		fmt.Fprintf(w, "capsaicin_throttled_total %d\n", atomic.LoadInt64(&stats.Throttled))
+		fmt.Fprintf(w, "capsaicin_destructive_skipped_total %d\n", atomic.LoadInt64(&stats.DestructiveSkipped))
+		p50, p95, p99 := latencyPercentilesSnapshot()
+		fmt.Fprintf(w, "capsaicin_latency_p50_ms %d\n", p50)
+		fmt.Fprintf(w, "capsaicin_latency_p95_ms %d\n", p95)
+		fmt.Fprintf(w, "capsaicin_latency_p99_ms %d\n", p99)
+	})
+
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "%s[WARN]%s Metrics server on %s stopped: %s\n", ColorYellow, ColorReset, addr, err)
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", err
+// watchHealth logs a periodic runtime health snapshot for -health-interval,
+// and warns if stats.Processed hasn't advanced across several consecutive
+// intervals while work remains queued -- the signature of a stuck worker
+// (deadlocked on a slow connection, wedged on a throttled target, etc.)
+func watchHealth(stats *Stats, taskChan chan Task, resultChan chan Result, interval time.Duration) {
+	const stuckThreshold = 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastProcessed int64 = -1
+	stuckStreak := 0
+
+	for range ticker.C {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		processed := atomic.LoadInt64(&stats.Processed)
+		total := atomic.LoadInt64(&stats.Total)
+
+		fmt.Fprintf(os.Stderr, "\n%s=== HEALTH SNAPSHOT (%s) ===%s\n", ColorNeonCyan+ColorBold, time.Now().Format(time.RFC3339), ColorReset)
+		fmt.Fprintf(os.Stderr, "Goroutines:     %d\n", runtime.NumGoroutine())
+		fmt.Fprintf(os.Stderr, "Heap Alloc:     %.1f MB\n", float64(mem.HeapAlloc)/(1024*1024))
+		fmt.Fprintf(os.Stderr, "GC Runs:        %d\n", mem.NumGC)
+		fmt.Fprintf(os.Stderr, "Processed:      %d / %d\n", processed, total)
+		fmt.Fprintf(os.Stderr, "Task Queue:     %d / %d\n", len(taskChan), cap(taskChan))
+		fmt.Fprintf(os.Stderr, "Result Queue:   %d / %d\n", len(resultChan), cap(resultChan))
+
+		if processed == lastProcessed && processed < total {
+			stuckStreak++
+			if stuckStreak >= stuckThreshold {
+				fmt.Fprintf(os.Stderr, "%s[WARN]%s No progress for %d consecutive health checks with %d tasks still queued -- workers may be stuck\n",
+					ColorYellow, ColorReset, stuckStreak, total-processed)
+			}
+		} else {
+			stuckStreak = 0
+		}
+		lastProcessed = processed
+
+		fmt.Fprintf(os.Stderr, "%s==============================%s\n\n", ColorNeonCyan+ColorBold, ColorReset)
 	}
+}
 
-	bodyContent := string(body)
-	server := resp.Header.Get("Server")
-	poweredBy := resp.Header.Get("X-Powered-By")
+func progressReporter(stats *Stats, ctx context.Context) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
 
-	result := &Result{
-		URL:        url,
-		StatusCode: resp.StatusCode,
-		Size:       len(body),
-		WordCount:  len(strings.Fields(bodyContent)),
-		LineCount:  strings.Count(bodyContent, "\n") + 1,
-		Method:     method,
-		Timestamp:  time.Now().Format(time.RFC3339),
-		Server:     server,
-		PoweredBy:  poweredBy,
-		UserAgent:  userAgent,
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			elapsed := time.Since(stats.StartTime).Seconds()
+			reqPerSec := float64(atomic.LoadInt64(&stats.Processed)) / elapsed
+			total := atomic.LoadInt64(&stats.Total)
+			processed := atomic.LoadInt64(&stats.Processed)
+			var progress float64
+			if total > 0 {
+				progress = float64(processed) / float64(total) * 100
+			}
+
+			throttled := atomic.LoadInt64(&stats.Throttled)
+			throttleTag := ""
+			if throttled > 0 {
+				throttleTag = fmt.Sprintf(" │ [⏳ %d throttled]", throttled)
+			}
+
+			fmt.Printf("\r%s[%.1f%%] │ [⚡ %d req/s] │ [✓ %d] │ [🔐 %d] │ [🔥 %d WAF] │ [✗ %d]%s%s",
+				ColorNeonCyan+ColorBold,
+				progress,
+				int(reqPerSec),
+				atomic.LoadInt64(&stats.Found),
+				atomic.LoadInt64(&stats.Secrets),
+				atomic.LoadInt64(&stats.WAFHits),
+				atomic.LoadInt64(&stats.Errors),
+				throttleTag,
+				ColorReset)
+		}
 	}
+}
 
-	// NEW FEATURE: WAF Detection
-	if wafName := detectWAF(resp); wafName != "" {
-		result.WAFDetected = wafName
+// tuiFindingsMax caps the -tui findings ring buffer at the last N results,
+// since the dashboard redraws in place rather than scrolling
+const tuiFindingsMax = 15
+
+var tuiFindingsMutex sync.Mutex
+var tuiFindings []Result
+
+// recordTUIFinding appends result to the -tui findings ring buffer, evicting
+// the oldest entry once it's full
+func recordTUIFinding(result Result) {
+	tuiFindingsMutex.Lock()
+	defer tuiFindingsMutex.Unlock()
+	tuiFindings = append(tuiFindings, result)
+	if len(tuiFindings) > tuiFindingsMax {
+		tuiFindings = tuiFindings[len(tuiFindings)-tuiFindingsMax:]
 	}
+}
 
-	result.CurlCommand = generateCurlCommand(url, method, userAgent, config)
+// tuiDashboard is -tui's live view: a progress bar, request-rate/finding
+// counters, and a scrolling table of the most recent findings, redrawn with
+// ANSI escapes every tick in place of the normal scrolling console output.
+// It's a stdlib-only approximation of a full terminal UI -- this build has
+// no bubbletea/tcell dependency to drive scrolling/filtering panels or
+// pause/thread-count keybindings with
+func tuiDashboard(stats *Stats, targets []string, ctx context.Context) {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
 
-	return result, bodyContent, nil
-}
+	const clearScreen = "\033[2J\033[H"
+	const barWidth = 40
 
-// NEW FEATURE: WAF Detection Engine
-func detectWAF(resp *http.Response) string {
-	for _, waf := range wafSignatures {
-		// Check Server header
-		if waf.ServerHeader != "" {
-			if server := resp.Header.Get("Server"); strings.Contains(strings.ToLower(server), strings.ToLower(waf.ServerHeader)) {
-				return waf.Name
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			elapsed := time.Since(stats.StartTime).Seconds()
+			total := atomic.LoadInt64(&stats.Total)
+			processed := atomic.LoadInt64(&stats.Processed)
+			var progress float64
+			if total > 0 {
+				progress = float64(processed) / float64(total) * 100
 			}
-		}
+			reqPerSec := float64(processed) / elapsed
 
-		// Check custom headers
-		if waf.CustomHeader != "" {
-			for header := range resp.Header {
-				if strings.Contains(strings.ToLower(header), strings.ToLower(waf.CustomHeader)) {
-					return waf.Name
-				}
+			filled := int(progress / 100 * barWidth)
+			if filled > barWidth {
+				filled = barWidth
 			}
-		}
-
-		// Check cookies
-		if waf.CookiePattern != "" {
-			for _, cookie := range resp.Cookies() {
-				if strings.Contains(cookie.Name, waf.CookiePattern) {
-					return waf.Name
+			bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s%s╔═ capsaicin -tui ═══════════════════════════════════════╗%s\n", clearScreen, ColorNeonCyan+ColorBold, ColorReset)
+			fmt.Fprintf(&b, "%s║%s Targets: %d  │  [%s] %5.1f%%  │  %d/%d req  │  %.0f req/s\n",
+				ColorNeonCyan+ColorBold, ColorReset, len(targets), bar, progress, processed, total, reqPerSec)
+			fmt.Fprintf(&b, "%s║%s Found: %d  Secrets: %d  WAF: %d  Errors: %d  Throttled: %d\n",
+				ColorNeonCyan+ColorBold, ColorReset,
+				atomic.LoadInt64(&stats.Found), atomic.LoadInt64(&stats.Secrets),
+				atomic.LoadInt64(&stats.WAFHits), atomic.LoadInt64(&stats.Errors),
+				atomic.LoadInt64(&stats.Throttled))
+			fmt.Fprintf(&b, "%s╟─ recent findings ──────────────────────────────────────╢%s\n", ColorNeonCyan+ColorBold, ColorReset)
+
+			tuiFindingsMutex.Lock()
+			findings := make([]Result, len(tuiFindings))
+			copy(findings, tuiFindings)
+			tuiFindingsMutex.Unlock()
+
+			if len(findings) == 0 {
+				fmt.Fprintf(&b, "  (none yet)\n")
+			}
+			for i := len(findings) - 1; i >= 0; i-- {
+				f := findings[i]
+				url := f.URL
+				if len(url) > 60 {
+					url = url[:57] + "..."
 				}
+				fmt.Fprintf(&b, "  [%3d] %-6s %s\n", f.StatusCode, f.Method, url)
 			}
+			fmt.Fprintf(&b, "%s╚═══════════════════════════════════════════════════════╝%s\n", ColorNeonCyan+ColorBold, ColorReset)
+
+			os.Stdout.WriteString(b.String())
 		}
 	}
+}
 
-	return ""
+// scanSummary is -summary's top-level shape: scan health and module outcomes
+// independent of the findings array, so dashboards don't need to parse every
+// Result just to know how a scan went
+type scanSummary struct {
+	GeneratedAt             string          `json:"generated_at"`
+	Duration                string          `json:"duration"`
+	Interrupted             bool            `json:"interrupted"`
+	RequestsTotal           int64           `json:"requests_total"`
+	FindingsTotal           int64           `json:"findings_total"`
+	SecretsTotal            int64           `json:"secrets_total"`
+	PIITotal                int64           `json:"pii_total"`
+	InfoDiscTotal           int64           `json:"info_disclosure_total"`
+	WAFHitsTotal            int64           `json:"waf_hits_total"`
+	ErrorsTotal             int64           `json:"errors_total"`
+	ThrottledTotal          int64           `json:"throttled_total"`
+	DestructiveSkippedTotal int64           `json:"destructive_skipped_total"`
+	RequestsPerSec          float64         `json:"requests_per_sec"`
+	Targets                 []targetSummary `json:"targets"`
+	// Modules breaks findings down by which discovery pass produced them
+	// (wordlist, recursion, method-fuzz, bypass, mutation, ...), plus
+	// attempt counts for the aggressive modules, so operators can see
+	// which features are earning their request budget
+	Modules []moduleStats `json:"modules,omitempty"`
 }
 
-func generateCurlCommand(url, method, userAgent string, config Config) string {
-	cmd := fmt.Sprintf(`curl -X %s "%s" -H "User-Agent: %s"`, method, url, userAgent)
-	for key, value := range config.CustomHeaders {
-		cmd += fmt.Sprintf(` -H "%s: %s"`, key, value)
-	}
-	return cmd
+// moduleStats is one module/technique's row in scanSummary.Modules.
+// Attempts is only populated for aggressive modules logged via
+// logAggressiveUse -- "wordlist" and "recursion" have no attempt concept
+// separate from their hit count, since every task they generate either
+// hits or 404s, it isn't a conditional probe
+type moduleStats struct {
+	Module   string `json:"module"`
+	Attempts int64  `json:"attempts,omitempty"`
+	Hits     int    `json:"hits"`
 }
 
-func detectSecrets(content string) []string {
-	var foundSecrets []string
-	secretMap := make(map[string]bool)
+// targetSummary rolls up one target's findings: status code breakdown, every
+// distinct WAF and Server/X-Powered-By fingerprint observed, and critical count
+type targetSummary struct {
+	Target       string      `json:"target"`
+	Findings     int         `json:"findings"`
+	Critical     int         `json:"critical"`
+	StatusCounts map[int]int `json:"status_counts,omitempty"`
+	WAFsDetected []string    `json:"wafs_detected,omitempty"`
+	Fingerprints []string    `json:"fingerprints,omitempty"`
+	// TLSVersionsSeen/WeakCiphersSeen list every distinct negotiated TLS
+	// version/cipher observed against this target; DeprecatedTLS is set if
+	// any of them was TLS 1.0/1.1, a quick audit signal for the summary
+	TLSVersionsSeen []string `json:"tls_versions_seen,omitempty"`
+	WeakCiphersSeen []string `json:"weak_ciphers_seen,omitempty"`
+	DeprecatedTLS   bool     `json:"deprecated_tls,omitempty"`
+	// RateLimitPolicy is the last observed X-RateLimit-*/RateLimit-* header
+	// trio for this target, a rough recon snapshot of its effective rate
+	// limit policy
+	RateLimitPolicy *rateLimitPolicy `json:"rate_limit_policy,omitempty"`
+	// LatencyP50Ms/P95Ms/P99Ms are response-time percentiles across every
+	// request against this target, distinguishing a genuinely slow app from
+	// one that's tarpitting the scan and informing rate decisions
+	LatencyP50Ms int64 `json:"latency_p50_ms,omitempty"`
+	LatencyP95Ms int64 `json:"latency_p95_ms,omitempty"`
+	LatencyP99Ms int64 `json:"latency_p99_ms,omitempty"`
+}
 
-	for _, pattern := range secretPatterns {
-		if pattern.Pattern.MatchString(content) {
-			if !secretMap[pattern.Name] {
-				foundSecrets = append(foundSecrets, pattern.Name)
-				secretMap[pattern.Name] = true
+// rateLimitPolicy is the raw X-RateLimit-*/RateLimit-* header values last
+// observed for a target, recon data surfaced in -summary
+type rateLimitPolicy struct {
+	Limit     string `json:"limit,omitempty"`
+	Remaining string `json:"remaining,omitempty"`
+	Reset     string `json:"reset,omitempty"`
+}
+
+// buildScanSummary aggregates results and stats into the structured
+// per-target shape written by -summary
+func buildScanSummary(results []Result, stats *Stats, elapsed time.Duration, interrupted bool) scanSummary {
+	byTarget := make(map[string]*targetSummary)
+	responseTimes := make(map[string][]int64)
+	var order []string
+	moduleHits := make(map[string]int)
+	var moduleOrder []string
+
+	for _, r := range results {
+		target := targetOf(r)
+		ts, ok := byTarget[target]
+		if !ok {
+			ts = &targetSummary{Target: target, StatusCounts: make(map[int]int)}
+			byTarget[target] = ts
+			order = append(order, target)
+		}
+
+		ts.Findings++
+		if r.Critical {
+			ts.Critical++
+		}
+		ts.StatusCounts[r.StatusCode]++
+		if r.WAFDetected != "" && !containsString(ts.WAFsDetected, r.WAFDetected) {
+			ts.WAFsDetected = append(ts.WAFsDetected, r.WAFDetected)
+		}
+		for _, fp := range []string{r.Server, r.PoweredBy} {
+			if fp != "" && !containsString(ts.Fingerprints, fp) {
+				ts.Fingerprints = append(ts.Fingerprints, fp)
+			}
+		}
+		if r.TLSVersion != "" {
+			if !containsString(ts.TLSVersionsSeen, r.TLSVersion) {
+				ts.TLSVersionsSeen = append(ts.TLSVersionsSeen, r.TLSVersion)
+			}
+			if isDeprecatedTLSVersion(r.TLSVersion) {
+				ts.DeprecatedTLS = true
+			}
+		}
+		if r.TLSCipher != "" && isWeakCipher(r.TLSCipher) && !containsString(ts.WeakCiphersSeen, r.TLSCipher) {
+			ts.WeakCiphersSeen = append(ts.WeakCiphersSeen, r.TLSCipher)
+		}
+		if r.RateLimitLimit != "" || r.RateLimitRemaining != "" || r.RateLimitReset != "" {
+			ts.RateLimitPolicy = &rateLimitPolicy{
+				Limit:     r.RateLimitLimit,
+				Remaining: r.RateLimitRemaining,
+				Reset:     r.RateLimitReset,
+			}
+		}
+		if r.ResponseTimeMs > 0 {
+			responseTimes[target] = append(responseTimes[target], r.ResponseTimeMs)
+		}
+		if r.Source != "" {
+			if moduleHits[r.Source] == 0 {
+				moduleOrder = append(moduleOrder, r.Source)
 			}
+			moduleHits[r.Source]++
 		}
 	}
 
-	return foundSecrets
-}
-
-func attemptBypass(url, userAgent string, config Config) (*Result, string) {
-	bypassHeaders := map[string]string{
-		"X-Forwarded-For":           "127.0.0.1",
-		"X-Original-URL":            extractPath(url),
-		"X-Rewrite-URL":             extractPath(url),
-		"X-Custom-IP-Authorization": "127.0.0.1",
-		"Client-IP":                 "127.0.0.1",
+	sort.Strings(order)
+	targets := make([]targetSummary, 0, len(order))
+	for _, target := range order {
+		ts := byTarget[target]
+		if times := responseTimes[target]; len(times) > 0 {
+			sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+			ts.LatencyP50Ms = latencyPercentile(times, 50)
+			ts.LatencyP95Ms = latencyPercentile(times, 95)
+			ts.LatencyP99Ms = latencyPercentile(times, 99)
+		}
+		targets = append(targets, *ts)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, ""
+	moduleAttemptCounts := make(map[string]int64)
+	moduleAttempts.Range(func(key, value interface{}) bool {
+		module := moduleKeyForTechnique(key.(string))
+		moduleAttemptCounts[module] += atomic.LoadInt64(value.(*int64))
+		if moduleHits[module] == 0 && !containsString(moduleOrder, module) {
+			moduleOrder = append(moduleOrder, module)
+		}
+		return true
+	})
+
+	sort.Strings(moduleOrder)
+	var modules []moduleStats
+	for _, module := range moduleOrder {
+		modules = append(modules, moduleStats{
+			Module:   module,
+			Attempts: moduleAttemptCounts[module],
+			Hits:     moduleHits[module],
+		})
 	}
 
-	req.Header.Set("User-Agent", userAgent)
+	return scanSummary{
+		GeneratedAt:             time.Now().Format(time.RFC3339),
+		Duration:                elapsed.Round(time.Millisecond).String(),
+		Interrupted:             interrupted,
+		RequestsTotal:           atomic.LoadInt64(&stats.Processed),
+		FindingsTotal:           atomic.LoadInt64(&stats.Found),
+		SecretsTotal:            atomic.LoadInt64(&stats.Secrets),
+		PIITotal:                atomic.LoadInt64(&stats.PII),
+		InfoDiscTotal:           atomic.LoadInt64(&stats.InfoDisclosure),
+		WAFHitsTotal:            atomic.LoadInt64(&stats.WAFHits),
+		ErrorsTotal:             atomic.LoadInt64(&stats.Errors),
+		ThrottledTotal:          atomic.LoadInt64(&stats.Throttled),
+		DestructiveSkippedTotal: atomic.LoadInt64(&stats.DestructiveSkipped),
+		RequestsPerSec:          float64(atomic.LoadInt64(&stats.Processed)) / elapsed.Seconds(),
+		Targets:                 targets,
+		Modules:                 modules,
+	}
+}
 
-	// Apply custom headers first
-	for key, value := range config.CustomHeaders {
-		req.Header.Set(key, value)
+// latencyPercentile returns the p-th percentile (0-100) of sorted, a
+// pre-sorted ascending slice of millisecond latencies, via nearest-rank
+func latencyPercentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
 	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
 
-	// Then apply bypass headers
-	for key, value := range bypassHeaders {
-		req.Header.Set(key, value)
+// containsString reports whether slice already holds value, used by
+// buildScanSummary to dedupe WAF names and fingerprints per target
+func containsString(slice []string, value string) bool {
+	for _, s := range slice {
+		if s == value {
+			return true
+		}
 	}
+	return false
+}
 
-	resp, err := httpClient.Do(req)
+// saveScanSummary writes a scanSummary as indented JSON to filename
+func saveScanSummary(summary scanSummary, filename string) error {
+	file, err := os.Create(filename)
 	if err != nil {
-		return nil, ""
+		return err
 	}
-	defer resp.Body.Close()
+	defer file.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
+
+func saveResults(results []Result, filename string) error {
+	file, err := os.Create(filename)
 	if err != nil {
-		return nil, ""
+		return err
 	}
+	defer file.Close()
 
-	bodyContent := string(body)
-	server := resp.Header.Get("Server")
-	poweredBy := resp.Header.Get("X-Powered-By")
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
 
-	curlCmd := fmt.Sprintf(`curl -X GET "%s" -H "User-Agent: %s" -H "X-Forwarded-For: 127.0.0.1" -H "X-Original-URL: %s"`,
-		url, userAgent, extractPath(url))
+// saveResultsByStatus writes discovered URLs into per-status-code files
+// (e.g. 200.txt, 403.txt) for consumption by follow-up tooling like httpx or nuclei
+func saveResultsByStatus(results []Result, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
 
-	result := &Result{
-		URL:        url + " [BYPASS]",
-		StatusCode: resp.StatusCode,
-		Size:       len(body),
-		WordCount:  len(strings.Fields(bodyContent)),
-		LineCount:  strings.Count(bodyContent, "\n") + 1,
-		Method:     "GET+BYPASS",
-		Timestamp:  time.Now().Format(time.RFC3339),
-		Server:     server,
-		PoweredBy:  poweredBy,
-		UserAgent:  userAgent,
-		CurlCommand: curlCmd,
+	byStatus := make(map[int][]string)
+	for _, result := range results {
+		byStatus[result.StatusCode] = append(byStatus[result.StatusCode], result.URL)
+	}
+
+	for status, urls := range byStatus {
+		filename := fmt.Sprintf("%s/%d.txt", strings.TrimSuffix(dir, "/"), status)
+		content := strings.Join(urls, "\n") + "\n"
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			return err
+		}
 	}
 
-	if wafName := detectWAF(resp); wafName != "" {
-		result.WAFDetected = wafName
-	}
+	return nil
+}
+
+// SARIF 2.1.0 output (https://sarifweb.azurewebsites.net/) so critical
+// bypasses, exposed secrets, and PII findings can be ingested directly by
+// GitHub/GitLab security dashboards from a CI job
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
 
-	return result, bodyContent
+type sarifRule struct {
+	ID                   string          `json:"id"`
+	Name                 string          `json:"name"`
+	ShortDescription     sarifMessage    `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfig `json:"defaultConfiguration"`
 }
 
-func generateMutations(path string) []string {
-	mutations := []string{
-		path + ".bak",
-		path + ".old",
-		path + ".backup",
-		path + "~",
-		path + ".swp",
-		"." + path + ".swp",
-		"_" + path,
-		path + ".txt",
-		path + ".orig",
-	}
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
 
-	if strings.Contains(path, ".") {
-		parts := strings.Split(path, ".")
-		base := strings.Join(parts[:len(parts)-1], ".")
-		mutations = append(mutations, base+".bak."+parts[len(parts)-1])
-	}
+type sarifMessage struct {
+	Text string `json:"text"`
+}
 
-	return mutations
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
 }
 
-func isDirectory(result *Result) bool {
-	if result.StatusCode == 301 || result.StatusCode == 302 || result.StatusCode == 403 {
-		return true
-	}
-	if strings.HasSuffix(result.URL, "/") {
-		return true
-	}
-	return false
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
 }
 
-func matchesSignature(result *Result, signatures []ResponseSignature) bool {
-	for _, sig := range signatures {
-		if result.StatusCode == sig.StatusCode {
-			if sig.Size == 0 {
-				continue
-			}
-			sizeDiff := float64(abs(result.Size-sig.Size)) / float64(sig.Size)
-			if sizeDiff < 0.05 {
-				return true
-			}
-		}
-	}
-	return false
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
 }
 
-func isInteresting(result *Result) bool {
-	if result.StatusCode >= 200 && result.StatusCode < 400 {
-		return true
-	}
-	if result.StatusCode == 401 || result.StatusCode == 403 {
-		return true
-	}
-	return false
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
 }
 
-func printResult(result Result) {
-	var color string
-	switch {
-	case result.StatusCode >= 200 && result.StatusCode < 300:
-		color = ColorNeonGreen
-	case result.StatusCode >= 300 && result.StatusCode < 400:
-		color = ColorBlue
-	case result.StatusCode >= 400 && result.StatusCode < 500:
-		color = ColorRed
-	case result.StatusCode >= 500:
-		color = ColorYellow
-	default:
-		color = ColorWhite
-	}
+// sarifRules are the fixed set of finding categories capsaicin reports
+// through SARIF; each Result is matched against these in order and may
+// produce more than one SARIF result (e.g. a critical bypass that also
+// leaked a secret)
+var sarifRules = []sarifRule{
+	{ID: "exposed-secret", Name: "ExposedSecret", ShortDescription: sarifMessage{Text: "A live credential or secret was found in a response body"}, DefaultConfiguration: sarifRuleConfig{Level: "error"}},
+	{ID: "critical-bypass", Name: "CriticalBypass", ShortDescription: sarifMessage{Text: "An authorization or WAF bypass exposed a restricted resource"}, DefaultConfiguration: sarifRuleConfig{Level: "error"}},
+	{ID: "exposed-pii", Name: "ExposedPII", ShortDescription: sarifMessage{Text: "Personally identifiable information was found in a response body"}, DefaultConfiguration: sarifRuleConfig{Level: "warning"}},
+}
 
-	critical := ""
-	if result.Critical {
-		critical = ColorOrange + ColorBold + " [⚡ CRITICAL]" + ColorReset
+// buildSARIFReport converts critical bypasses, secret findings, and PII
+// findings into a SARIF run; results that are merely "interesting" but not
+// security-relevant are left out, since SARIF consumers expect findings,
+// not a full crawl log
+func buildSARIFReport(results []Result) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "capsaicin",
+				Version:        "1.5",
+				InformationURI: "https://github.com/hawtsauceTR/capsaicin",
+				Rules:          sarifRules,
+			},
+		},
 	}
 
-	// WAF Detection output with high visibility
-	wafInfo := ""
-	if result.WAFDetected != "" {
-		wafInfo = BgMagenta + ColorWhite + ColorBold + " [🔥 WAF: " + result.WAFDetected + "] " + ColorReset
+	for _, r := range results {
+		if r.SecretFound {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    "exposed-secret",
+				Level:     "error",
+				Message:   sarifMessage{Text: fmt.Sprintf("Secret types found: %s", strings.Join(r.SecretTypes, ", "))},
+				Locations: []sarifLocation{sarifLocationFor(r.URL)},
+			})
+		}
+		if r.Critical {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    "critical-bypass",
+				Level:     "error",
+				Message:   sarifMessage{Text: fmt.Sprintf("Critical finding via %s (status %d)", r.Source, r.StatusCode)},
+				Locations: []sarifLocation{sarifLocationFor(r.URL)},
+			})
+		}
+		if r.PIIFound {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    "exposed-pii",
+				Level:     "warning",
+				Message:   sarifMessage{Text: fmt.Sprintf("PII types found: %s", strings.Join(r.PIITypes, ", "))},
+				Locations: []sarifLocation{sarifLocationFor(r.URL)},
+			})
+		}
 	}
 
-	secretInfo := ""
-	if result.SecretFound {
-		secretInfo = BgRed + ColorWhite + ColorBold + " [🔐 " + strings.Join(result.SecretTypes, ", ") + "] " + ColorReset
+	return sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
 	}
+}
 
-	methodInfo := ""
-	if result.Method != "GET" && result.Method != "GET+BYPASS" {
-		methodInfo = ColorPurple + " [METHOD: " + result.Method + "]" + ColorReset
+func sarifLocationFor(resultURL string) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: resultURL},
+		},
 	}
+}
 
-	techInfo := ""
-	if result.Server != "" {
-		techInfo += fmt.Sprintf(" [%s]", result.Server)
-	}
-	if result.PoweredBy != "" {
-		techInfo += fmt.Sprintf(" [%s]", result.PoweredBy)
+// saveSARIFReport writes the SARIF document for -sarif
+func saveSARIFReport(results []Result, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	fmt.Printf("%s%-4d%s │ Size: %s%-7d%s │ %s%s%s%s%s%s%s%s\n",
-		color+ColorBold, result.StatusCode, ColorReset,
-		color, result.Size, ColorReset,
-		color, result.URL, ColorReset,
-		ColorCyan+techInfo+ColorReset,
-		methodInfo,
-		critical,
-		wafInfo,
-		secretInfo)
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildSARIFReport(results))
 }
 
-func progressReporter(stats *Stats, ctx context.Context) {
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
+// DiffStatus classifies a result against a prior baseline scan
+type DiffStatus string
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			elapsed := time.Since(stats.StartTime).Seconds()
-			reqPerSec := float64(atomic.LoadInt64(&stats.Processed)) / elapsed
-			total := atomic.LoadInt64(&stats.Total)
-			processed := atomic.LoadInt64(&stats.Processed)
-			var progress float64
-			if total > 0 {
-				progress = float64(processed) / float64(total) * 100
-			}
+const (
+	DiffNew       DiffStatus = "new"
+	DiffChanged   DiffStatus = "changed"
+	DiffUnchanged DiffStatus = "unchanged"
+	DiffResolved  DiffStatus = "resolved"
+)
 
-			fmt.Printf("\r%s[%.1f%%] │ [⚡ %d req/s] │ [✓ %d] │ [🔐 %d] │ [🔥 %d WAF] │ [✗ %d]%s",
-				ColorNeonCyan+ColorBold,
-				progress,
-				int(reqPerSec),
-				atomic.LoadInt64(&stats.Found),
-				atomic.LoadInt64(&stats.Secrets),
-				atomic.LoadInt64(&stats.WAFHits),
-				atomic.LoadInt64(&stats.Errors),
-				ColorReset)
-		}
+// loadBaseline reads a prior scan's JSON output (as written by saveResults)
+func loadBaseline(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
 	}
+	return results, nil
 }
 
-func saveResults(results []Result, filename string) error {
-	file, err := os.Create(filename)
+// loadKnownURLs reads a prior scan's JSON output (the same shape saveResults
+// writes) and returns the set of URLs it already confirmed, for -exclude-known
+func loadKnownURLs(path string) (map[string]bool, error) {
+	results, err := loadBaseline(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
+	known := make(map[string]bool, len(results))
+	for _, r := range results {
+		known[r.URL] = true
+	}
+	return known, nil
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(results)
+// diffAgainstBaseline compares the current results against a baseline scan,
+// keyed by URL, and returns the diff status for every URL seen in either scan
+func diffAgainstBaseline(current, baseline []Result) map[string]DiffStatus {
+	baselineByURL := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		baselineByURL[r.URL] = r
+	}
+	currentByURL := make(map[string]bool, len(current))
+
+	diff := make(map[string]DiffStatus)
+	for _, r := range current {
+		currentByURL[r.URL] = true
+		old, existed := baselineByURL[r.URL]
+		switch {
+		case !existed:
+			diff[r.URL] = DiffNew
+		case old.StatusCode != r.StatusCode || old.Size != r.Size:
+			diff[r.URL] = DiffChanged
+		default:
+			diff[r.URL] = DiffUnchanged
+		}
+	}
+	for _, r := range baseline {
+		if !currentByURL[r.URL] {
+			diff[r.URL] = DiffResolved
+		}
+	}
+	return diff
+}
+
+// applyFindingAging stamps each current result's FirstSeen/LastSeen by
+// matching it against a baseline scan keyed by URL: a finding present in the
+// baseline carries its FirstSeen forward unchanged, while a brand-new finding
+// is first-seen now. Running repeated scans with -baseline pointed at the
+// previous run's -o output turns that output into a lightweight persistent
+// store, so this is the "monitor mode" aging mechanism.
+func applyFindingAging(current, baseline []Result) []Result {
+	now := time.Now().Format(time.RFC3339)
+
+	baselineByURL := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		baselineByURL[r.URL] = r
+	}
+
+	for i := range current {
+		if old, ok := baselineByURL[current[i].URL]; ok && old.FirstSeen != "" {
+			current[i].FirstSeen = old.FirstSeen
+		} else {
+			current[i].FirstSeen = now
+		}
+		current[i].LastSeen = now
+	}
+	return current
 }
 
-func generateHTMLReport(results []Result, filename string, config Config) error {
+func generateHTMLReport(results []Result, filename string, config Config, baseline []Result) error {
 	htmlTemplate := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -1213,6 +8655,36 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 			font-size: 0.85em;
 			animation: pulse 2s infinite;
 		}
+		.secret-classification {
+			display: inline-block;
+			margin-left: 6px;
+			padding: 3px 8px;
+			border-radius: 6px;
+			font-size: 0.8em;
+			color: #ffcccc;
+			background: rgba(255,0,0,0.15);
+			border: 1px solid rgba(255,0,0,0.4);
+		}
+		.live-secret-badge {
+			display: inline-block;
+			margin-left: 6px;
+			background: #ff0000;
+			color: #fff;
+			padding: 4px 10px;
+			border-radius: 6px;
+			font-weight: bold;
+			font-size: 0.85em;
+			animation: pulse 1s infinite;
+		}
+		.pii-badge {
+			display: inline-block;
+			background: rgba(255,165,0,0.3);
+			border: 2px solid #ffa500;
+			padding: 4px 12px;
+			border-radius: 6px;
+			font-weight: bold;
+			font-size: 0.85em;
+		}
 		.waf-badge {
 			display: inline-block;
 			background: rgba(255,0,255,0.4);
@@ -1231,6 +8703,40 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 			0%%, 100%% { box-shadow: 0 0 5px #ff00ff; }
 			50%% { box-shadow: 0 0 20px #ff00ff; }
 		}
+		.tag-badge {
+			display: inline-block;
+			background: rgba(0,255,136,0.2);
+			border: 1px solid #00ff88;
+			padding: 3px 10px;
+			border-radius: 6px;
+			font-size: 0.85em;
+			margin-left: 4px;
+		}
+		.category-badge {
+			display: inline-block;
+			background: rgba(0,200,255,0.2);
+			border: 1px solid #00c8ff;
+			padding: 3px 10px;
+			border-radius: 6px;
+			font-size: 0.85em;
+			margin-left: 4px;
+		}
+		.diff-tabs {
+			display: flex;
+			gap: 10px;
+			margin-bottom: 20px;
+		}
+		.diff-tab {
+			padding: 10px 20px;
+			border-radius: 8px;
+			background: rgba(255,255,255,0.1);
+			cursor: pointer;
+			font-weight: bold;
+		}
+		.diff-tab.active { background: linear-gradient(135deg, #ff0080, #ff8c00); }
+		.diff-new-badge { color: #00ff88; font-weight: bold; }
+		.diff-changed-badge { color: #ffaa00; font-weight: bold; }
+		.diff-resolved-badge { color: #888; font-weight: bold; text-decoration: line-through; }
 		.tech-badge {
 			display: inline-block;
 			background: rgba(100,200,255,0.2);
@@ -1298,6 +8804,10 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 			</div>
 		</div>
 
+		%s
+
+		%s
+
 		<div class="search-box">
 			<input type="text" id="searchInput" placeholder="🔍 Search findings (URL, status, server, secrets, WAF...)">
 		</div>
@@ -1320,13 +8830,28 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 	</div>
 
 	<script>
-		document.getElementById('searchInput').addEventListener('input', function(e) {
-			const searchTerm = e.target.value.toLowerCase();
+		let activeDiffTab = 'all';
+
+		function applyFilters() {
+			const searchTerm = document.getElementById('searchInput').value.toLowerCase();
 			const rows = document.querySelectorAll('#resultsTable tbody tr');
-			
+
 			rows.forEach(row => {
 				const text = row.textContent.toLowerCase();
-				row.style.display = text.includes(searchTerm) ? '' : 'none';
+				const matchesSearch = text.includes(searchTerm);
+				const matchesTab = activeDiffTab === 'all' || row.dataset.diff === activeDiffTab;
+				row.style.display = (matchesSearch && matchesTab) ? '' : 'none';
+			});
+		}
+
+		document.getElementById('searchInput').addEventListener('input', applyFilters);
+
+		document.querySelectorAll('.diff-tab').forEach(tab => {
+			tab.addEventListener('click', () => {
+				document.querySelectorAll('.diff-tab').forEach(t => t.classList.remove('active'));
+				tab.classList.add('active');
+				activeDiffTab = tab.dataset.tab;
+				applyFilters();
 			});
 		});
 
@@ -1339,6 +8864,38 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 </body>
 </html>`
 
+	var diff map[string]DiffStatus
+	reportResults := results
+	if baseline != nil {
+		diff = diffAgainstBaseline(results, baseline)
+		seen := make(map[string]bool, len(results))
+		for _, r := range results {
+			seen[r.URL] = true
+		}
+		for _, r := range baseline {
+			if !seen[r.URL] {
+				reportResults = append(reportResults, r)
+			}
+		}
+	}
+
+	vhostMatrixHTML := buildVhostMatrixHTML(reportResults)
+
+	var tabsHTML string
+	if diff != nil {
+		counts := map[DiffStatus]int{}
+		for _, status := range diff {
+			counts[status]++
+		}
+		tabsHTML = fmt.Sprintf(`
+		<div class="diff-tabs">
+			<div class="diff-tab active" data-tab="all">All (%d)</div>
+			<div class="diff-tab" data-tab="new">🆕 New (%d)</div>
+			<div class="diff-tab" data-tab="changed">♻️ Changed (%d)</div>
+			<div class="diff-tab" data-tab="resolved">✅ Resolved (%d)</div>
+		</div>`, len(reportResults), counts[DiffNew], counts[DiffChanged], counts[DiffResolved])
+	}
+
 	var tableRows strings.Builder
 	count2xx := 0
 	count3xx := 0
@@ -1346,7 +8903,7 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 	countSecrets := 0
 	countWAF := 0
 
-	for _, result := range results {
+	for _, result := range reportResults {
 		statusClass := "status-200"
 		if result.StatusCode >= 300 && result.StatusCode < 400 {
 			statusClass = "status-300"
@@ -1377,6 +8934,17 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 		secretBadge := ""
 		if result.SecretFound {
 			secretBadge = fmt.Sprintf(`<span class="secret-badge">🔐 %s</span>`, strings.Join(result.SecretTypes, ", "))
+			if len(result.SecretClassification) > 0 {
+				secretBadge += fmt.Sprintf(`<span class="secret-classification">%s</span>`, strings.Join(result.SecretClassification, "; "))
+			}
+			if len(result.LiveSecrets) > 0 {
+				secretBadge += fmt.Sprintf(`<span class="live-secret-badge">☠️ LIVE: %s</span>`, strings.Join(result.LiveSecrets, ", "))
+			}
+		}
+
+		piiBadge := ""
+		if result.PIIFound {
+			piiBadge = fmt.Sprintf(`<span class="pii-badge">🪪 %s</span>`, strings.Join(result.PIITypes, ", "))
 		}
 
 		wafBadge := ""
@@ -1384,6 +8952,21 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 			wafBadge = fmt.Sprintf(`<span class="waf-badge">🔥 WAF: %s</span>`, result.WAFDetected)
 		}
 
+		tagBadge := ""
+		if len(result.Tags) > 0 {
+			tagBadge = fmt.Sprintf(`<span class="tag-badge">🏷 %s</span>`, strings.Join(result.Tags, ", "))
+		}
+
+		noteBadge := ""
+		if len(result.Notes) > 0 {
+			noteBadge = fmt.Sprintf(`<span class="tag-badge">📝 %s</span>`, strings.Join(result.Notes, " | "))
+		}
+
+		categoryBadge := ""
+		if result.Category != "" {
+			categoryBadge = fmt.Sprintf(`<span class="category-badge">%s</span>`, result.Category)
+		}
+
 		techInfo := ""
 		if result.Server != "" {
 			techInfo += fmt.Sprintf(`<span class="tech-badge">%s</span>`, result.Server)
@@ -1392,12 +8975,56 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 			techInfo += fmt.Sprintf(`<span class="tech-badge">%s</span>`, result.PoweredBy)
 		}
 
-		securityInfo := secretBadge + " " + wafBadge
+		diffBadge := ""
+		diffAttr := ""
+		if diff != nil {
+			status := diff[result.URL]
+			diffAttr = string(status)
+			switch status {
+			case DiffNew:
+				diffBadge = `<span class="diff-new-badge">🆕 NEW</span>`
+			case DiffChanged:
+				diffBadge = `<span class="diff-changed-badge">♻️ CHANGED</span>`
+			case DiffResolved:
+				diffBadge = `<span class="diff-resolved-badge">✅ RESOLVED</span>`
+			}
+		}
+
+		altBadge := ""
+		if len(result.AlternateURLs) > 0 {
+			altBadge = fmt.Sprintf(`<span class="tag-badge">🔗 also: %s</span>`, strings.Join(result.AlternateURLs, ", "))
+		}
+
+		ageBadge := ""
+		if result.FirstSeen != "" {
+			if firstSeen, err := time.Parse(time.RFC3339, result.FirstSeen); err == nil {
+				if age := time.Since(firstSeen).Round(time.Hour); age >= 24*time.Hour {
+					ageBadge = fmt.Sprintf(`<span class="tag-badge">⏳ %d days old</span>`, int(age.Hours()/24))
+				}
+			}
+		}
+
+		headerAnomalyBadge := ""
+		if len(result.HeaderAnomalies) > 0 {
+			headerAnomalyBadge = fmt.Sprintf(`<span class="secret-badge">⚠ %s</span>`, strings.Join(result.HeaderAnomalies, "; "))
+		}
+
+		infoDisclosureBadge := ""
+		if len(result.InfoDisclosure) > 0 {
+			infoDisclosureBadge = fmt.Sprintf(`<span class="pii-badge">🕵 %s</span>`, strings.Join(result.InfoDisclosure, ", "))
+		}
+
+		headDiffBadge := ""
+		if result.HeadGetDiscrepancy != "" {
+			headDiffBadge = fmt.Sprintf(`<span class="tag-badge">HEAD/GET: %s</span>`, result.HeadGetDiscrepancy)
+		}
+
+		securityInfo := secretBadge + " " + piiBadge + " " + wafBadge + " " + tagBadge + " " + noteBadge + " " + categoryBadge + " " + diffBadge + " " + altBadge + " " + ageBadge + " " + headerAnomalyBadge + " " + infoDisclosureBadge + " " + headDiffBadge
 
 		escapedCurl := strings.ReplaceAll(result.CurlCommand, `"`, `&quot;`)
 
 		tableRows.WriteString(fmt.Sprintf(`
-				<tr>
+				<tr data-diff="%s" data-category="%s">
 					<td class="%s">%d</td>
 					<td><code>%s</code> %s</td>
 					<td>%d bytes</td>
@@ -1405,33 +9032,128 @@ func generateHTMLReport(results []Result, filename string, config Config) error
 					<td>%s</td>
 					<td><button class="curl-btn" onclick='copyCurl("%s")'>Copy Curl</button></td>
 				</tr>`,
-			statusClass, result.StatusCode, result.URL, criticalBadge,
+			diffAttr, result.Category, statusClass, result.StatusCode, result.URL, criticalBadge,
 			result.Size, techInfo, securityInfo, escapedCurl))
 	}
 
 	finalHTML := fmt.Sprintf(htmlTemplate,
 		time.Now().Format("2006-01-02 15:04:05"),
-		len(results),
+		len(reportResults),
 		count2xx,
 		count3xx,
 		countCritical,
 		countSecrets,
 		countWAF,
+		tabsHTML,
+		vhostMatrixHTML,
 		tableRows.String())
 
 	return os.WriteFile(filename, []byte(finalHTML), 0644)
 }
 
+// buildVhostMatrixHTML renders a vhost x interesting-path table when any
+// result carries a Vhost (i.e. -vhost ran combined with path fuzzing), so
+// findings against the same IP are attributable to the right virtual host
+func buildVhostMatrixHTML(results []Result) string {
+	pathsByVhost := make(map[string]map[string]int)
+	var vhosts []string
+	for _, r := range results {
+		if r.Vhost == "" {
+			continue
+		}
+		if pathsByVhost[r.Vhost] == nil {
+			pathsByVhost[r.Vhost] = make(map[string]int)
+			vhosts = append(vhosts, r.Vhost)
+		}
+		pathsByVhost[r.Vhost][extractPath(r.URL)] = r.StatusCode
+	}
+	if len(vhosts) == 0 {
+		return ""
+	}
+	sort.Strings(vhosts)
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-box"><strong>🧭 Vhost &times; Path Matrix</strong><table><thead><tr><th>Virtual Host</th><th>Interesting Paths</th></tr></thead><tbody>`)
+	for _, vhost := range vhosts {
+		paths := pathsByVhost[vhost]
+		names := make([]string, 0, len(paths))
+		for path := range paths {
+			names = append(names, path)
+		}
+		sort.Strings(names)
+		var cells strings.Builder
+		for _, path := range names {
+			cells.WriteString(fmt.Sprintf(`<span class="tag-badge">%s (%d)</span> `, path, paths[path]))
+		}
+		sb.WriteString(fmt.Sprintf(`<tr><td>%s</td><td>%s</td></tr>`, vhost, cells.String()))
+	}
+	sb.WriteString(`</tbody></table></div>`)
+	return sb.String()
+}
+
+// verboseSampled decides whether this particular verbose line should print,
+// letting -v-sample throttle console I/O on high-thread scans
+func verboseSampled(config Config) bool {
+	if config.VerboseSample >= 100 {
+		return true
+	}
+	return rand.Float64()*100 < config.VerboseSample
+}
+
+// localModeActive mirrors config.LocalMode for getRandomUserAgent, which
+// has no config parameter at any of its call sites
+var localModeActive atomic.Bool
+
 func getRandomUserAgent() string {
+	if localModeActive.Load() {
+		return userAgents[0]
+	}
 	return userAgents[rand.Intn(len(userAgents))]
 }
 
-func extractPath(url string) string {
-	parts := strings.SplitN(url, "/", 4)
-	if len(parts) >= 4 {
-		return "/" + parts[3]
+// firstNonPrivateTarget returns the first target whose host doesn't
+// resolve to a loopback or RFC1918/ULA private address, or "" if every
+// target does -- the safety gate for -local, which disables stealth/WAF
+// logic that should never be turned off against a real public target
+func firstNonPrivateTarget(targets []string) string {
+	for _, target := range targets {
+		parsed, err := url.Parse(target)
+		if err != nil || parsed.Hostname() == "" {
+			return target
+		}
+		host := parsed.Hostname()
+		if ips, err := net.LookupIP(host); err == nil {
+			private := false
+			for _, ip := range ips {
+				if ip.IsLoopback() || ip.IsPrivate() {
+					private = true
+					break
+				}
+			}
+			if private {
+				continue
+			}
+		}
+		if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate()) {
+			continue
+		}
+		return target
+	}
+	return ""
+}
+
+// extractPath returns the path (+ query) component of a URL, using net/url
+// so IPv6 literal hosts like https://[2001:db8::1]:8443/path are handled
+// correctly instead of breaking on the bracketed colons
+func extractPath(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	if parsed.RawQuery != "" {
+		return parsed.Path + "?" + parsed.RawQuery
 	}
-	return "/"
+	return parsed.Path
 }
 
 func abs(n int) int {